@@ -3,12 +3,58 @@ package bitmap
 import (
 	"math/bits"
 	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	wordBits  = 64
+	wordShift = 6 // log2(wordBits)
+	wordMask  = wordBits - 1
 )
 
-// Bitmap represents a thread-safe bitmap using a byte array
+var ErrIndexOutOfRange = errors.New("bitmap index out of range")
+
+// Interface is the set of bitmap methods shared by process-local and remote bitmaps,
+// so that users such as the bloom filters can run on either
+type Interface interface {
+	// Set sets the bit at the given index to 1
+	Set(index int)
+	// MSet sets the bits at all given indexes to 1
+	MSet(indexes []int)
+	// Clear clears the bit at the given index to 0
+	Clear(index int)
+	// IsSet checks if the bit at the given index is set to 1
+	IsSet(index int) bool
+	// Count returns the number of bits set to 1
+	Count() int
+	// Size returns the capacity of the bitmap in bits
+	Size() int
+}
+
+// AllSetter is implemented by bitmaps that check many bits faster in one call than one by one
+type AllSetter interface {
+	// AllSet checks if the bits at all given indexes are set to 1
+	AllSet(indexes []int) bool
+}
+
+var _ Interface = (*Bitmap)(nil)
+
+// Bitmap represents a thread-safe bitmap backed by 64-bit words.
+// Single bit updates use atomic compare-and-swap on the owning word,
+// so concurrent Set/Clear/IsSet calls never block each other.
 type Bitmap struct {
-	mutex sync.Mutex
-	bits  []byte
+	data     atomic.Pointer[bitset]
+	growable bool
+	limit    int          // size a growable bitmap never grows past
+	resize   sync.RWMutex // held shared by writers of a growable bitmap, exclusively while it grows
+}
+
+// bitset is the storage of a bitmap. A growable bitmap publishes a new bitset when it grows,
+// the words of a published bitset are only ever modified atomically.
+type bitset struct {
+	words []uint64
 	size  int // Track original size for bounds checking
 }
 
@@ -17,23 +63,48 @@ func NewBitmap(size int) *Bitmap {
 	if size < 0 {
 		panic("bitmap size must be non-negative")
 	}
-	return &Bitmap{
-		bits: make([]byte, (size+7)/8),
-		size: size,
+	b := &Bitmap{}
+	b.data.Store(newBitset(size))
+	return b
+}
+
+// NewGrowableBitmap creates a new Bitmap with the given initial size (in bits)
+// that grows on Set instead of panicking on indexes past the end, up to limit bits.
+// Setting a bit at limit or past it is out of range, so that an untrusted index cannot exhaust memory.
+// Reading or clearing a bit past the end of a growable bitmap is not an error, the bit is simply 0.
+func NewGrowableBitmap(size, limit int) *Bitmap {
+	if limit < size {
+		panic("bitmap limit must not be below its size")
+	}
+	b := NewBitmap(size)
+	b.growable = true
+	b.limit = limit
+	return b
+}
+
+// emptyBitset is the storage of a zero value Bitmap, an empty bitmap
+var emptyBitset = &bitset{}
+
+func newBitset(size int) *bitset {
+	return &bitset{
+		words: make([]uint64, wordCount(size)),
+		size:  size,
 	}
 }
 
 // Set sets the bit at the given index to 1
 func (b *Bitmap) Set(index int) {
-	b.validateIndex(index)
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	b.bits[index/8] |= 1 << (index % 8)
+	if b.growable && index >= 0 && index < b.limit {
+		b.grow(index + 1)
+		defer b.lockWrite()()
+	}
+	s := b.load()
+	s.validateIndex(index)
+	orWord(&s.words[index>>wordShift], 1<<(index&wordMask))
 }
 
+// MSet sets the bits at all given indexes to 1
 func (b *Bitmap) MSet(indexes []int) {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
 	for _, index := range indexes {
 		b.Set(index)
 	}
@@ -41,39 +112,189 @@ func (b *Bitmap) MSet(indexes []int) {
 
 // Clear clears the bit at the given index to 0
 func (b *Bitmap) Clear(index int) {
-	b.validateIndex(index)
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	b.bits[index/8] &^= 1 << (index % 8)
+	if b.growable {
+		defer b.lockWrite()()
+		if index >= b.load().size {
+			return
+		}
+	}
+	s := b.load()
+	s.validateIndex(index)
+	andNotWord(&s.words[index>>wordShift], 1<<(index&wordMask))
 }
 
 // IsSet checks if the bit at the given index is set to 1
 func (b *Bitmap) IsSet(index int) bool {
-	b.validateIndex(index)
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	return b.bits[index/8]&(1<<(index%8)) != 0
+	s := b.load()
+	if b.growable && index >= s.size {
+		return false
+	}
+	s.validateIndex(index)
+	return atomic.LoadUint64(&s.words[index>>wordShift])&(1<<(index&wordMask)) != 0
+}
+
+// TrySet is like Set but returns ErrIndexOutOfRange instead of panicking
+func (b *Bitmap) TrySet(index int) error {
+	if err := b.checkIndex(index); err != nil {
+		return err
+	}
+	b.Set(index)
+	return nil
+}
+
+// TryClear is like Clear but returns ErrIndexOutOfRange instead of panicking
+func (b *Bitmap) TryClear(index int) error {
+	if err := b.checkIndex(index); err != nil {
+		return err
+	}
+	b.Clear(index)
+	return nil
+}
+
+// TryIsSet is like IsSet but returns ErrIndexOutOfRange instead of panicking
+func (b *Bitmap) TryIsSet(index int) (bool, error) {
+	if err := b.checkIndex(index); err != nil {
+		return false, err
+	}
+	return b.IsSet(index), nil
 }
 
 // Count returns the number of bits set to 1 using efficient bit counting
 func (b *Bitmap) Count() int {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
+	s := b.load()
 	count := 0
-	for _, byteVal := range b.bits {
-		count += bits.OnesCount8(byteVal)
+	for i := range s.words {
+		count += bits.OnesCount64(atomic.LoadUint64(&s.words[i]))
 	}
 	return count
 }
 
 // Size returns the capacity of the bitmap in bits
 func (b *Bitmap) Size() int {
-	return b.size
+	return b.load().size
+}
+
+// Growable reports whether the bitmap grows on Set past its end
+func (b *Bitmap) Growable() bool {
+	return b.growable
+}
+
+// Limit returns the size a growable bitmap never grows past, Size() for other bitmaps
+func (b *Bitmap) Limit() int {
+	if !b.growable {
+		return b.Size()
+	}
+	return b.limit
+}
+
+func (b *Bitmap) load() *bitset {
+	if s := b.data.Load(); s != nil {
+		return s
+	}
+	return emptyBitset
+}
+
+// lockWrite holds off growing while the caller modifies words, returns the unlock function
+func (b *Bitmap) lockWrite() func() {
+	if !b.growable {
+		return func() {}
+	}
+	b.resize.RLock()
+	return b.resize.RUnlock
+}
+
+// grow extends a growable bitmap to at least size bits.
+// The word capacity is doubled so that growing bit by bit stays amortized O(1).
+func (b *Bitmap) grow(size int) {
+	if b.load().size >= size {
+		return
+	}
+
+	b.resize.Lock()
+	defer b.resize.Unlock()
+
+	s := b.load()
+	if s.size >= size {
+		return
+	}
+
+	n := wordCount(size)
+	words := s.words
+	if n <= cap(words) {
+		// words past the old length have never been written and are still zero
+		words = words[:n]
+	} else {
+		words = make([]uint64, n, max(n, 2*cap(words)))
+		for i := range s.words {
+			words[i] = atomic.LoadUint64(&s.words[i])
+		}
+	}
+	b.data.Store(&bitset{words: words, size: size})
+}
+
+// checkIndex returns ErrIndexOutOfRange if index is not addressable,
+// indexes past the end of a growable bitmap are addressable up to its limit
+func (b *Bitmap) checkIndex(index int) error {
+	if index < 0 || index >= b.Limit() {
+		return errors.Wrapf(ErrIndexOutOfRange, "index %d size %d limit %d", index, b.Size(), b.Limit())
+	}
+	return nil
 }
 
 // validateIndex checks if index is within valid range
-func (b *Bitmap) validateIndex(index int) {
-	if index < 0 || index >= b.size {
+func (s *bitset) validateIndex(index int) {
+	if index < 0 || index >= s.size {
 		panic("bitmap index out of range")
 	}
 }
+
+// loadWord atomically reads the i-th word, treating words past the end as zero
+func (s *bitset) loadWord(i int) uint64 {
+	if i >= len(s.words) {
+		return 0
+	}
+	return atomic.LoadUint64(&s.words[i])
+}
+
+// wordMask returns the mask of valid bits in the i-th word
+func (s *bitset) wordMask(i int) uint64 {
+	if rem := s.size - i<<wordShift; rem < wordBits {
+		return 1<<max(rem, 0) - 1
+	}
+	return ^uint64(0)
+}
+
+// wordCount returns the number of words needed to hold size bits
+func wordCount(size int) int {
+	return (size + wordMask) >> wordShift
+}
+
+// orWord atomically sets the mask bits of the word, skipping the write when they are already set
+func orWord(addr *uint64, mask uint64) {
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&mask == mask || atomic.CompareAndSwapUint64(addr, old, old|mask) {
+			return
+		}
+	}
+}
+
+// andNotWord atomically clears the mask bits of the word, skipping the write when they are already clear
+func andNotWord(addr *uint64, mask uint64) {
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&mask == 0 || atomic.CompareAndSwapUint64(addr, old, old&^mask) {
+			return
+		}
+	}
+}
+
+// updateWord atomically replaces the word with fn(word)
+func updateWord(addr *uint64, fn func(uint64) uint64) {
+	for {
+		old := atomic.LoadUint64(addr)
+		if atomic.CompareAndSwapUint64(addr, old, fn(old)) {
+			return
+		}
+	}
+}
@@ -69,6 +69,26 @@ func TestNewBitmap(t *testing.T) {
 	})
 }
 
+func TestZeroValueBitmap(t *testing.T) {
+	var bm Bitmap
+	assert.Equal(t, 0, bm.Size())
+	assert.Equal(t, 0, bm.Count())
+	assert.Equal(t, 0, bm.Rank(0))
+	_, ok := bm.NextSet(0)
+	assert.False(t, ok)
+	assert.Equal(t, 0, bm.Clone().Size())
+
+	_, err := bm.TryIsSet(0)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	assert.Panics(t, func() { bm.Set(0) })
+
+	data, err := bm.MarshalBinary()
+	assert.NoError(t, err)
+	restored := NewBitmap(8)
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, 0, restored.Size())
+}
+
 func TestSetAndIsSet(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -172,6 +192,141 @@ func TestConcurrency(t *testing.T) {
 	}
 }
 
+func TestMSet(t *testing.T) {
+	bm := NewBitmap(200)
+	bm.MSet([]int{0, 63, 64, 127, 199, 63})
+
+	assert.Equal(t, 5, bm.Count())
+	for _, i := range []int{0, 63, 64, 127, 199} {
+		assert.Truef(t, bm.IsSet(i), "Bit %d is not set", i)
+	}
+	assert.False(t, bm.IsSet(1))
+
+	assert.Panics(t, func() { bm.MSet([]int{200}) }, "index out of range")
+}
+
+func TestConcurrentSameWord(t *testing.T) {
+	bm := NewBitmap(64)
+	var wg sync.WaitGroup
+
+	// All goroutines contend on a single word
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 64; i++ {
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				bm.Set(idx)
+			}(i)
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, 64, bm.Count())
+
+	for i := 0; i < 64; i += 2 {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			bm.Clear(idx)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 32, bm.Count())
+}
+
+func TestTryOps(t *testing.T) {
+	bm := NewBitmap(10)
+
+	assert.NoError(t, bm.TrySet(9))
+	ok, err := bm.TryIsSet(9)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, bm.TryClear(9))
+	assert.False(t, bm.IsSet(9))
+
+	for _, index := range []int{-1, 10, 1 << 40} {
+		assert.ErrorIs(t, bm.TrySet(index), ErrIndexOutOfRange)
+		assert.ErrorIs(t, bm.TryClear(index), ErrIndexOutOfRange)
+		_, err := bm.TryIsSet(index)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	}
+	assert.Equal(t, 0, bm.Count())
+}
+
+func TestGrowableBitmap(t *testing.T) {
+	bm := NewGrowableBitmap(0, 4000)
+	assert.True(t, bm.Growable())
+	assert.False(t, NewBitmap(1).Growable())
+
+	assert.False(t, bm.IsSet(100), "bits past the end read as 0")
+	bm.Clear(100) // Shouldn't panic or grow
+	assert.Equal(t, 0, bm.Size())
+
+	bm.Set(5)
+	assert.Equal(t, 6, bm.Size())
+	bm.Set(1000)
+	assert.Equal(t, 1001, bm.Size())
+	assert.True(t, bm.IsSet(5))
+	assert.True(t, bm.IsSet(1000))
+	assert.Equal(t, 2, bm.Count())
+
+	assert.NoError(t, bm.TrySet(2000))
+	assert.Equal(t, 2001, bm.Size())
+	assert.ErrorIs(t, bm.TrySet(-1), ErrIndexOutOfRange)
+	assert.Panics(t, func() { bm.Set(-1) })
+
+	c := bm.Clone()
+	assert.True(t, c.Growable())
+	assert.Equal(t, 4000, c.Limit())
+	c.Set(3000)
+	assert.Equal(t, 2001, bm.Size())
+}
+
+func TestGrowableBitmapLimit(t *testing.T) {
+	bm := NewGrowableBitmap(10, 100)
+	assert.Equal(t, 100, bm.Limit())
+	assert.Equal(t, 10, NewBitmap(10).Limit())
+
+	assert.NoError(t, bm.TrySet(99))
+	assert.Equal(t, 100, bm.Size())
+	for _, index := range []int{100, 1 << 40} {
+		assert.ErrorIs(t, bm.TrySet(index), ErrIndexOutOfRange)
+		assert.ErrorIs(t, bm.TryClear(index), ErrIndexOutOfRange)
+		_, err := bm.TryIsSet(index)
+		assert.ErrorIs(t, err, ErrIndexOutOfRange)
+	}
+	assert.Panics(t, func() { bm.Set(1 << 40) })
+	assert.Equal(t, 100, bm.Size(), "indexes past the limit must not grow the bitmap")
+	assert.Equal(t, 1, bm.Count())
+
+	assert.Panics(t, func() { NewGrowableBitmap(10, 9) })
+}
+
+func TestGrowableConcurrency(t *testing.T) {
+	bm := NewGrowableBitmap(0, 20_000)
+	var wg sync.WaitGroup
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for i := offset; i < 20_000; i += 8 {
+				bm.Set(i)
+				if i%3 == 0 {
+					bm.Clear(i)
+				}
+				_ = bm.IsSet(i)
+				_ = bm.Count()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20_000, bm.Size())
+	for i := 0; i < 20_000; i++ {
+		assert.Equalf(t, i%3 != 0, bm.IsSet(i), "bit %d", i)
+	}
+}
+
 // Benchmark tests
 func BenchmarkSet(b *testing.B) {
 	bm := NewBitmap(b.N * 8)
@@ -203,6 +358,26 @@ func BenchmarkCount(b *testing.B) {
 	}
 }
 
+func BenchmarkGrowableSet(b *testing.B) {
+	bm := NewGrowableBitmap(0, 1<<40)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.Set(i)
+	}
+}
+
+func BenchmarkMSet(b *testing.B) {
+	bm := NewBitmap(1024 * 1024)
+	indexes := make([]int, 1024)
+	for i := range indexes {
+		indexes[i] = (i * 1021) % bm.Size()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.MSet(indexes)
+	}
+}
+
 func BenchmarkConcurrentAccess(b *testing.B) {
 	bm := NewBitmap(1024)
 	b.RunParallel(func(pb *testing.PB) {
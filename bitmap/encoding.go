@@ -0,0 +1,138 @@
+package bitmap
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// Binary format of a marshaled Bitmap:
+//
+//	version  1 byte
+//	order    1 byte   bit order of the payload, see orderLSB and orderMSB
+//	flags    1 byte   see flagGrowable
+//	size     uvarint  size in bits
+//	payload  (size+7)/8 bytes
+//
+// MarshalBinary always writes the payload in Redis order, so the payload equals Bytes().
+const (
+	encodingVersion = 1
+
+	orderLSB = 0 // bit i is 1<<(i%8) of byte i/8
+	orderMSB = 1 // bit i is 0x80>>(i%8) of byte i/8, the order of Redis SETBIT/GETBIT
+
+	flagGrowable = 1 << 0
+
+	maxSize = 1<<(bits.UintSize-1) - 1 - wordMask // largest size accepted from an encoded bitmap
+)
+
+var ErrInvalidEncoding = errors.New("invalid bitmap encoding")
+
+var (
+	_ encoding.BinaryMarshaler   = (*Bitmap)(nil)
+	_ encoding.BinaryUnmarshaler = (*Bitmap)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (b *Bitmap) MarshalBinary() ([]byte, error) {
+	s := b.load()
+
+	var flags byte
+	if b.growable {
+		flags |= flagGrowable
+	}
+
+	data := make([]byte, 0, 3+binary.MaxVarintLen64+byteCount(s.size))
+	data = append(data, encodingVersion, orderMSB, flags)
+	data = binary.AppendUvarint(data, uint64(s.size))
+	return s.appendBytes(data), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the bitmap.
+// The limit is not encoded: a growable bitmap keeps its limit and rejects data of a larger size,
+// other bitmaps decoding a growable one never grow past the decoded size.
+// It must not be called concurrently with other methods of the bitmap.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return errors.Wrap(ErrInvalidEncoding, "header too short")
+	}
+	version, order, flags := data[0], data[1], data[2]
+	if version != encodingVersion {
+		return errors.Wrapf(ErrInvalidEncoding, "unsupported version %d", version)
+	}
+	if order != orderLSB && order != orderMSB {
+		return errors.Wrapf(ErrInvalidEncoding, "unknown bit order %d", order)
+	}
+
+	size, n := binary.Uvarint(data[3:])
+	if n <= 0 || size > uint64(maxSize) {
+		return errors.Wrap(ErrInvalidEncoding, "invalid size")
+	}
+	payload := data[3+n:]
+
+	limit := int(size)
+	if b.growable {
+		if size > uint64(b.limit) {
+			return errors.Wrapf(ErrInvalidEncoding, "size %d exceeds limit %d", size, b.limit)
+		}
+		limit = b.limit
+	}
+	if len(payload) != byteCount(int(size)) {
+		return errors.Wrapf(ErrInvalidEncoding, "payload length %d does not match size %d", len(payload), size)
+	}
+
+	s := newBitset(int(size))
+	for i, v := range payload {
+		if order == orderMSB {
+			v = bits.Reverse8(v)
+		}
+		s.words[i>>3] |= uint64(v) << (8 * (i & 7))
+	}
+	if len(s.words) > 0 && s.words[len(s.words)-1]&^s.wordMask(len(s.words)-1) != 0 {
+		return errors.Wrap(ErrInvalidEncoding, "bits set past size")
+	}
+
+	b.resize.Lock()
+	defer b.resize.Unlock()
+	b.growable = flags&flagGrowable != 0
+	b.limit = limit
+	b.data.Store(s)
+	return nil
+}
+
+// Bytes returns the bitmap as bytes in Redis SETBIT/GETBIT order (most significant bit first),
+// so the result can be written with SET and read back by GETBIT, BITCOUNT and BITPOS
+func (b *Bitmap) Bytes() []byte {
+	s := b.load()
+	return s.appendBytes(make([]byte, 0, byteCount(s.size)))
+}
+
+// FromBytes creates a Bitmap of len(data)*8 bits from bytes in Redis SETBIT/GETBIT order,
+// such as the value returned by GET on a key written with SETBIT
+func FromBytes(data []byte) *Bitmap {
+	b := NewBitmap(len(data) * 8)
+	s := b.load()
+	for i, v := range data {
+		s.words[i>>3] |= uint64(bits.Reverse8(v)) << (8 * (i & 7))
+	}
+	return b
+}
+
+// appendBytes appends the bits in Redis order to data
+func (s *bitset) appendBytes(data []byte) []byte {
+	n := byteCount(s.size)
+	for i := 0; i < n; i += 8 {
+		word := s.loadWord(i >> 3)
+		for j := i; j < i+8 && j < n; j++ {
+			data = append(data, bits.Reverse8(byte(word>>(8*(j-i)))))
+		}
+	}
+	return data
+}
+
+// byteCount returns the number of bytes needed to hold size bits
+func byteCount(size int) int {
+	return (size + 7) >> 3
+}
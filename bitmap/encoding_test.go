@@ -0,0 +1,144 @@
+package bitmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		setBits  []int
+		growable bool
+	}{
+		{"empty", 0, nil, false},
+		{"partial byte", 5, []int{0, 4}, false},
+		{"partial word", 70, []int{1, 63, 64, 69}, false},
+		{"exact words", 128, []int{0, 127}, false},
+		{"growable", 10, []int{9}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := NewBitmap(tt.size)
+			if tt.growable {
+				bm = NewGrowableBitmap(tt.size, 4*tt.size+64)
+			}
+			bm.MSet(tt.setBits)
+
+			data, err := bm.MarshalBinary()
+			require.NoError(t, err)
+
+			got := NewBitmap(0)
+			if tt.growable {
+				got = NewGrowableBitmap(0, bm.Limit())
+			}
+			require.NoError(t, got.UnmarshalBinary(data))
+			assert.Equal(t, tt.size, got.Size())
+			assert.Equal(t, tt.growable, got.Growable())
+			assert.Equal(t, bm.Limit(), got.Limit())
+			assert.Equal(t, setBits(bm), setBits(got))
+			assert.Equal(t, bm.Bytes(), data[len(data)-byteCount(tt.size):], "payload is in Redis order")
+		})
+	}
+}
+
+func TestUnmarshalLSBOrder(t *testing.T) {
+	// bits 0 and 9 in least significant bit first order
+	data := []byte{encodingVersion, orderLSB, 0, 10, 0x01, 0x02}
+	bm := NewBitmap(0)
+	require.NoError(t, bm.UnmarshalBinary(data))
+	assert.Equal(t, []int{0, 9}, setBits(bm))
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", []byte{encodingVersion, orderMSB}},
+		{"bad version", []byte{99, orderMSB, 0, 0}},
+		{"bad order", []byte{encodingVersion, 7, 0, 0}},
+		{"missing size", []byte{encodingVersion, orderMSB, 0}},
+		{"short payload", []byte{encodingVersion, orderMSB, 0, 16, 0xff}},
+		{"long payload", []byte{encodingVersion, orderMSB, 0, 8, 0xff, 0xff}},
+		{"bits past size", []byte{encodingVersion, orderMSB, 0, 4, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := newBitmapWith(8, 1)
+			assert.ErrorIs(t, bm.UnmarshalBinary(tt.data), ErrInvalidEncoding)
+			assert.Equal(t, []int{1}, setBits(bm), "failed unmarshal must not modify the bitmap")
+		})
+	}
+}
+
+func TestUnmarshalGrowable(t *testing.T) {
+	bm := NewGrowableBitmap(16, 1000)
+	bm.Set(15)
+	data, err := bm.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("keeps the limit of the bitmap", func(t *testing.T) {
+		got := NewGrowableBitmap(0, 64)
+		require.NoError(t, got.UnmarshalBinary(data))
+		assert.Equal(t, 16, got.Size())
+		assert.Equal(t, 64, got.Limit())
+		assert.ErrorIs(t, got.TrySet(64), ErrIndexOutOfRange)
+	})
+
+	t.Run("size past the limit", func(t *testing.T) {
+		got := NewGrowableBitmap(1, 8)
+		got.Set(0)
+		assert.ErrorIs(t, got.UnmarshalBinary(data), ErrInvalidEncoding)
+		assert.Equal(t, []int{0}, setBits(got), "failed unmarshal must not modify the bitmap")
+	})
+
+	t.Run("bitmap without a limit", func(t *testing.T) {
+		got := NewBitmap(0)
+		require.NoError(t, got.UnmarshalBinary(data))
+		assert.True(t, got.Growable())
+		assert.Equal(t, 16, got.Limit())
+		assert.ErrorIs(t, got.TrySet(16), ErrIndexOutOfRange)
+		assert.False(t, got.IsSet(100))
+	})
+}
+
+func TestRedisBitOrder(t *testing.T) {
+	// SETBIT key 0 1; SETBIT key 7 1; SETBIT key 9 1 => GET key == "\x81\x40"
+	bm := newBitmapWith(16, 0, 7, 9)
+	assert.Equal(t, []byte{0x81, 0x40}, bm.Bytes())
+
+	got := FromBytes([]byte{0x81, 0x40})
+	assert.Equal(t, 16, got.Size())
+	assert.Equal(t, []int{0, 7, 9}, setBits(got))
+
+	t.Run("partial byte", func(t *testing.T) {
+		bm := newBitmapWith(3, 2)
+		assert.Equal(t, []byte{0x20}, bm.Bytes())
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		bm := NewBitmap(1000)
+		for i := 0; i < 1000; i += 7 {
+			bm.Set(i)
+		}
+		assert.Equal(t, setBits(bm), setBits(FromBytes(bm.Bytes())))
+	})
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	bm := NewBitmap(1 << 20)
+	for i := 0; i < bm.Size(); i += 3 {
+		bm.Set(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = bm.MarshalBinary()
+	}
+}
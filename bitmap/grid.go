@@ -0,0 +1,229 @@
+package bitmap
+
+import (
+	"encoding"
+	"encoding/binary"
+	"iter"
+
+	"github.com/pkg/errors"
+)
+
+// BitGrid represents a thread-safe two-dimensional grid of bits, such as the explored or occupied tiles of a map.
+// Cells are stored row by row in a Bitmap, cell (x, y) is bit y*width+x.
+type BitGrid struct {
+	width  int
+	height int
+	bits   *Bitmap
+}
+
+// NewBitGrid creates a new BitGrid with the given width and height (in cells)
+func NewBitGrid(width, height int) *BitGrid {
+	if width < 0 || height < 0 {
+		panic("bit grid size must be non-negative")
+	}
+	return &BitGrid{
+		width:  width,
+		height: height,
+		bits:   NewBitmap(width * height),
+	}
+}
+
+// Width returns the number of columns
+func (g *BitGrid) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows
+func (g *BitGrid) Height() int {
+	return g.height
+}
+
+// InBounds checks if (x, y) is a cell of the grid
+func (g *BitGrid) InBounds(x, y int) bool {
+	return x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+// Set sets the cell (x, y) to 1
+func (g *BitGrid) Set(x, y int) {
+	g.bits.Set(g.index(x, y))
+}
+
+// Clear clears the cell (x, y) to 0
+func (g *BitGrid) Clear(x, y int) {
+	g.bits.Clear(g.index(x, y))
+}
+
+// IsSet checks if the cell (x, y) is set to 1
+func (g *BitGrid) IsSet(x, y int) bool {
+	return g.bits.IsSet(g.index(x, y))
+}
+
+// Count returns the number of cells set to 1
+func (g *BitGrid) Count() int {
+	return g.bits.Count()
+}
+
+// FillRect sets the cells of the w*h rectangle with top left corner (x, y) to 1.
+// The part of the rectangle outside the grid is ignored.
+func (g *BitGrid) FillRect(x, y, w, h int) {
+	g.updateRect(x, y, w, h, g.bits.SetRange)
+}
+
+// ClearRect clears the cells of the w*h rectangle with top left corner (x, y) to 0.
+// The part of the rectangle outside the grid is ignored.
+func (g *BitGrid) ClearRect(x, y, w, h int) {
+	g.updateRect(x, y, w, h, g.bits.ClearRange)
+}
+
+// Row returns an iterator over the x of the cells set to 1 in row y, from left to right
+func (g *BitGrid) Row(y int) iter.Seq[int] {
+	if y < 0 || y >= g.height {
+		panic("bit grid coordinate out of range")
+	}
+	return func(yield func(int) bool) {
+		start, end := y*g.width, (y+1)*g.width
+		for i, ok := g.bits.NextSet(start); ok && i < end; i, ok = g.bits.NextSet(i + 1) {
+			if !yield(i - start) {
+				return
+			}
+		}
+	}
+}
+
+// Column returns an iterator over the y of the cells set to 1 in column x, from top to bottom
+func (g *BitGrid) Column(x int) iter.Seq[int] {
+	if x < 0 || x >= g.width {
+		panic("bit grid coordinate out of range")
+	}
+	return func(yield func(int) bool) {
+		for y := 0; y < g.height; y++ {
+			if g.bits.IsSet(y*g.width+x) && !yield(y) {
+				return
+			}
+		}
+	}
+}
+
+// RegionSize returns the number of cells in the region around (x, y), that is the cells
+// in the same state as (x, y) reachable from it through horizontal and vertical neighbours
+func (g *BitGrid) RegionSize(x, y int) int {
+	visited := NewBitmap(g.width * g.height)
+	return g.flood(g.index(x, y), visited)
+}
+
+// RegionCount returns the number of separate regions of cells set to 1,
+// cells being connected through horizontal and vertical neighbours
+func (g *BitGrid) RegionCount() int {
+	visited := NewBitmap(g.width * g.height)
+	count := 0
+	for i := range g.bits.SetBits() {
+		if !visited.IsSet(i) {
+			g.flood(i, visited)
+			count++
+		}
+	}
+	return count
+}
+
+// flood marks the region of cell start in visited and returns its size
+func (g *BitGrid) flood(start int, visited *Bitmap) int {
+	state := g.bits.IsSet(start)
+	visited.Set(start)
+	stack := []int{start}
+	size := 0
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		size++
+
+		x := i % g.width
+		for _, n := range [4]int{i - g.width, i + g.width, i - 1, i + 1} {
+			if n < 0 || n >= g.width*g.height ||
+				(n == i-1 && x == 0) || (n == i+1 && x == g.width-1) ||
+				visited.IsSet(n) || g.bits.IsSet(n) != state {
+				continue
+			}
+			visited.Set(n)
+			stack = append(stack, n)
+		}
+	}
+	return size
+}
+
+// updateRect applies update to the part of every row of the rectangle inside the grid
+func (g *BitGrid) updateRect(x, y, w, h int, update func(start, end int)) {
+	x0, x1 := max(x, 0), min(x+w, g.width)
+	y0, y1 := max(y, 0), min(y+h, g.height)
+	if x0 >= x1 {
+		return
+	}
+	for row := y0; row < y1; row++ {
+		update(row*g.width+x0, row*g.width+x1)
+	}
+}
+
+// index returns the bit index of the cell (x, y)
+func (g *BitGrid) index(x, y int) int {
+	if !g.InBounds(x, y) {
+		panic("bit grid coordinate out of range")
+	}
+	return y*g.width + x
+}
+
+// Binary format of a marshaled BitGrid:
+//
+//	version  1 byte
+//	width    uvarint
+//	height   uvarint
+//	bits     the marshaled Bitmap of the cells
+const gridEncodingVersion = 1
+
+var (
+	_ encoding.BinaryMarshaler   = (*BitGrid)(nil)
+	_ encoding.BinaryUnmarshaler = (*BitGrid)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (g *BitGrid) MarshalBinary() ([]byte, error) {
+	bits, err := g.bits.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(bits))
+	data = append(data, gridEncodingVersion)
+	data = binary.AppendUvarint(data, uint64(g.width))
+	data = binary.AppendUvarint(data, uint64(g.height))
+	return append(data, bits...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the grid.
+// It must not be called concurrently with other methods of the grid.
+func (g *BitGrid) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != gridEncodingVersion {
+		return errors.Wrap(ErrInvalidEncoding, "unsupported bit grid version")
+	}
+	data = data[1:]
+
+	width, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errors.Wrap(ErrInvalidEncoding, "invalid bit grid width")
+	}
+	data = data[n:]
+	height, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errors.Wrap(ErrInvalidEncoding, "invalid bit grid height")
+	}
+	data = data[n:]
+
+	bits := NewBitmap(0)
+	if err := bits.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if width != 0 && height > uint64(maxSize)/width || uint64(bits.Size()) != width*height {
+		return errors.Wrapf(ErrInvalidEncoding, "bit grid %dx%d does not match %d bits", width, height, bits.Size())
+	}
+
+	g.width, g.height, g.bits = int(width), int(height), bits
+	return nil
+}
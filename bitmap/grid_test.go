@@ -0,0 +1,186 @@
+package bitmap
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitGridBasic(t *testing.T) {
+	g := NewBitGrid(10, 5)
+	assert.Equal(t, 10, g.Width())
+	assert.Equal(t, 5, g.Height())
+	assert.Equal(t, 0, g.Count())
+
+	g.Set(0, 0)
+	g.Set(9, 4)
+	g.Set(3, 2)
+	assert.True(t, g.IsSet(0, 0))
+	assert.True(t, g.IsSet(9, 4))
+	assert.True(t, g.IsSet(3, 2))
+	assert.False(t, g.IsSet(2, 3))
+	assert.Equal(t, 3, g.Count())
+
+	g.Clear(3, 2)
+	assert.False(t, g.IsSet(3, 2))
+	assert.Equal(t, 2, g.Count())
+
+	assert.True(t, g.InBounds(9, 4))
+	assert.False(t, g.InBounds(10, 0))
+	assert.False(t, g.InBounds(0, -1))
+
+	assert.Panics(t, func() { g.Set(10, 0) })
+	assert.Panics(t, func() { g.IsSet(0, 5) })
+	assert.Panics(t, func() { g.Clear(-1, 0) })
+	assert.Panics(t, func() { NewBitGrid(-1, 1) })
+}
+
+func TestBitGridRect(t *testing.T) {
+	g := NewBitGrid(100, 50)
+	g.FillRect(10, 5, 70, 3)
+	assert.Equal(t, 210, g.Count())
+	assert.True(t, g.IsSet(10, 5))
+	assert.True(t, g.IsSet(79, 7))
+	assert.False(t, g.IsSet(80, 7))
+	assert.False(t, g.IsSet(10, 8))
+	assert.False(t, g.IsSet(9, 5))
+
+	g.ClearRect(20, 6, 10, 1)
+	assert.Equal(t, 200, g.Count())
+	assert.False(t, g.IsSet(25, 6))
+	assert.True(t, g.IsSet(25, 5))
+
+	t.Run("clipped", func(t *testing.T) {
+		g := NewBitGrid(8, 8)
+		g.FillRect(-2, -2, 4, 4)
+		assert.Equal(t, 4, g.Count())
+		g.FillRect(6, 6, 10, 10)
+		assert.Equal(t, 8, g.Count())
+		g.FillRect(20, 0, 5, 5) // entirely outside
+		g.FillRect(0, 0, 0, 5)
+		assert.Equal(t, 8, g.Count())
+		g.ClearRect(-100, -100, 200, 200)
+		assert.Equal(t, 0, g.Count())
+	})
+}
+
+func TestBitGridRowColumn(t *testing.T) {
+	g := NewBitGrid(70, 3)
+	g.Set(0, 1)
+	g.Set(63, 1)
+	g.Set(69, 1)
+	g.Set(69, 0)
+	g.Set(0, 2)
+
+	assert.Equal(t, []int{0, 63, 69}, slices.Collect(g.Row(1)))
+	assert.Equal(t, []int{69}, slices.Collect(g.Row(0)))
+	assert.Equal(t, []int{0}, slices.Collect(g.Row(2)))
+	assert.Equal(t, []int{1, 2}, slices.Collect(g.Column(0)))
+	assert.Equal(t, []int{0, 1}, slices.Collect(g.Column(69)))
+	assert.Empty(t, slices.Collect(g.Column(5)))
+
+	for x := range g.Row(1) {
+		assert.Equal(t, 0, x, "iteration stops when yield returns false")
+		break
+	}
+
+	assert.Panics(t, func() { g.Row(3) })
+	assert.Panics(t, func() { g.Column(70) })
+}
+
+func TestBitGridRegions(t *testing.T) {
+	rows := []string{
+		"#..#.",
+		"#..##",
+		"...#.",
+		"##...",
+	}
+	g := NewBitGrid(5, len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				g.Set(x, y)
+			}
+		}
+	}
+
+	assert.Equal(t, 2, g.RegionSize(0, 0))
+	assert.Equal(t, 4, g.RegionSize(3, 0))
+	assert.Equal(t, 2, g.RegionSize(1, 3))
+	assert.Equal(t, 11, g.RegionSize(1, 0))
+	assert.Equal(t, 1, g.RegionSize(4, 0), "regions do not wrap around rows")
+	assert.Equal(t, 3, g.RegionCount())
+
+	g.FillRect(0, 0, 5, 4)
+	assert.Equal(t, 1, g.RegionCount())
+	assert.Equal(t, 20, g.RegionSize(2, 2))
+
+	assert.Equal(t, 0, NewBitGrid(4, 4).RegionCount())
+	assert.Panics(t, func() { g.RegionSize(5, 0) })
+}
+
+func TestBitGridMarshalBinary(t *testing.T) {
+	g := NewBitGrid(33, 7)
+	g.FillRect(3, 1, 20, 4)
+	g.Set(32, 6)
+
+	data, err := g.MarshalBinary()
+	require.NoError(t, err)
+
+	got := NewBitGrid(1, 1)
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, 33, got.Width())
+	assert.Equal(t, 7, got.Height())
+	assert.Equal(t, g.Count(), got.Count())
+	for y := 0; y < 7; y++ {
+		assert.Equal(t, slices.Collect(g.Row(y)), slices.Collect(got.Row(y)))
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		bits, err := NewBitmap(10).MarshalBinary()
+		require.NoError(t, err)
+
+		tests := []struct {
+			name string
+			data []byte
+		}{
+			{"empty", nil},
+			{"bad version", []byte{9, 1, 1}},
+			{"missing height", []byte{gridEncodingVersion, 1}},
+			{"truncated", data[:len(data)-1]},
+			{"size mismatch", append([]byte{gridEncodingVersion, 3, 3}, bits...)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := NewBitGrid(2, 2)
+				got.Set(1, 1)
+				assert.ErrorIs(t, got.UnmarshalBinary(tt.data), ErrInvalidEncoding)
+				assert.True(t, got.IsSet(1, 1), "failed unmarshal must not modify the grid")
+			})
+		}
+	})
+}
+
+func TestBitGridConcurrency(t *testing.T) {
+	g := NewBitGrid(64, 64)
+	var wg sync.WaitGroup
+	for row := 0; row < 8; row++ {
+		wg.Add(1)
+		go func(row int) {
+			defer wg.Done()
+			for y := row; y < 64; y += 8 {
+				for x := 0; x < 64; x++ {
+					g.Set(x, y)
+					_ = g.IsSet(x, y)
+				}
+				g.ClearRect(0, y, 32, 1)
+			}
+		}(row)
+	}
+	wg.Wait()
+	assert.Equal(t, 64*32, g.Count())
+	assert.Equal(t, 1, g.RegionCount())
+}
@@ -0,0 +1,115 @@
+package bitmap
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// NextSet returns the index of the first bit set to 1 at or after index i.
+// Returns false if there is no such bit.
+func (b *Bitmap) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	s := b.load()
+	if i >= s.size {
+		return -1, false
+	}
+
+	w := i >> wordShift
+	word := s.loadWord(w) &^ (1<<(i&wordMask) - 1)
+	for {
+		if word != 0 {
+			return w<<wordShift + bits.TrailingZeros64(word), true
+		}
+		w++
+		if w >= len(s.words) {
+			return -1, false
+		}
+		word = s.loadWord(w)
+	}
+}
+
+// NextClear returns the index of the first bit set to 0 at or after index i.
+// Returns false if every bit from i to the end of the bitmap is set.
+func (b *Bitmap) NextClear(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	s := b.load()
+	if i >= s.size {
+		return -1, false
+	}
+
+	w := i >> wordShift
+	word := ^s.loadWord(w) & s.wordMask(w) &^ (1<<(i&wordMask) - 1)
+	for {
+		if word != 0 {
+			return w<<wordShift + bits.TrailingZeros64(word), true
+		}
+		w++
+		if w >= len(s.words) {
+			return -1, false
+		}
+		word = ^s.loadWord(w) & s.wordMask(w)
+	}
+}
+
+// SetBits returns an iterator over the indexes of the bits set to 1 in ascending order.
+// Each word is loaded once, so bits set behind the iterator are not observed.
+func (b *Bitmap) SetBits() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		s := b.load()
+		for w := range s.words {
+			word := s.loadWord(w)
+			for word != 0 {
+				if !yield(w<<wordShift + bits.TrailingZeros64(word)) {
+					return
+				}
+				word &= word - 1
+			}
+		}
+	}
+}
+
+// Rank returns the number of bits set to 1 before index i.
+// i must be in the range [0, Size()].
+func (b *Bitmap) Rank(i int) int {
+	s := b.load()
+	if i < 0 || i > s.size {
+		panic("bitmap index out of range")
+	}
+
+	count := 0
+	w := i >> wordShift
+	for j := 0; j < w; j++ {
+		count += bits.OnesCount64(s.loadWord(j))
+	}
+	if rem := i & wordMask; rem != 0 {
+		count += bits.OnesCount64(s.loadWord(w) & (1<<rem - 1))
+	}
+	return count
+}
+
+// Select returns the index of the n-th bit set to 1, counting from 0.
+// Returns false if fewer than n+1 bits are set.
+func (b *Bitmap) Select(n int) (int, bool) {
+	if n < 0 {
+		return -1, false
+	}
+
+	s := b.load()
+	for w := range s.words {
+		word := s.loadWord(w)
+		c := bits.OnesCount64(word)
+		if n >= c {
+			n -= c
+			continue
+		}
+		for ; n > 0; n-- {
+			word &= word - 1
+		}
+		return w<<wordShift + bits.TrailingZeros64(word), true
+	}
+	return -1, false
+}
@@ -0,0 +1,131 @@
+package bitmap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSet(t *testing.T) {
+	bm := newBitmapWith(200, 3, 64, 65, 199)
+
+	tests := []struct {
+		name   string
+		from   int
+		want   int
+		wantOk bool
+	}{
+		{"negative start", -5, 3, true},
+		{"from zero", 0, 3, true},
+		{"exact hit", 3, 3, true},
+		{"cross word", 4, 64, true},
+		{"next in word", 65, 65, true},
+		{"last bit", 66, 199, true},
+		{"past end", 200, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bm.NextSet(tt.from)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("none left", func(t *testing.T) {
+		_, ok := newBitmapWith(130, 1).NextSet(2)
+		assert.False(t, ok)
+	})
+}
+
+func TestNextClear(t *testing.T) {
+	bm := NewBitmap(130)
+	for i := 0; i < 128; i++ {
+		bm.Set(i)
+	}
+	bm.Clear(5)
+
+	got, ok := bm.NextClear(0)
+	assert.True(t, ok)
+	assert.Equal(t, 5, got)
+
+	got, ok = bm.NextClear(6)
+	assert.True(t, ok)
+	assert.Equal(t, 128, got)
+
+	bm.Set(128)
+	bm.Set(129)
+	_, ok = bm.NextClear(6)
+	assert.False(t, ok, "bits past size must not be reported as clear")
+}
+
+func TestSetBits(t *testing.T) {
+	bm := newBitmapWith(300, 0, 63, 64, 128, 299)
+	assert.Equal(t, []int{0, 63, 64, 128, 299}, slices.Collect(bm.SetBits()))
+
+	t.Run("early stop", func(t *testing.T) {
+		var got []int
+		for i := range bm.SetBits() {
+			if i > 64 {
+				break
+			}
+			got = append(got, i)
+		}
+		assert.Equal(t, []int{0, 63, 64}, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, slices.Collect(NewBitmap(100).SetBits()))
+	})
+}
+
+func TestRankAndSelect(t *testing.T) {
+	positions := []int{2, 63, 64, 100, 191, 192}
+	bm := newBitmapWith(193, positions...)
+
+	assert.Equal(t, 0, bm.Rank(0))
+	assert.Equal(t, 0, bm.Rank(2))
+	assert.Equal(t, 1, bm.Rank(3))
+	assert.Equal(t, 2, bm.Rank(64))
+	assert.Equal(t, 3, bm.Rank(65))
+	assert.Equal(t, 6, bm.Rank(193))
+	assert.Panics(t, func() { bm.Rank(194) })
+	assert.Panics(t, func() { bm.Rank(-1) })
+
+	for n, pos := range positions {
+		got, ok := bm.Select(n)
+		assert.True(t, ok)
+		assert.Equal(t, pos, got)
+		assert.Equal(t, n, bm.Rank(got))
+	}
+
+	_, ok := bm.Select(len(positions))
+	assert.False(t, ok)
+	_, ok = bm.Select(-1)
+	assert.False(t, ok)
+}
+
+func BenchmarkSetBits(b *testing.B) {
+	bm := NewBitmap(1 << 20)
+	for i := 0; i < bm.Size(); i += 7 {
+		bm.Set(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range bm.SetBits() {
+		}
+	}
+}
+
+func BenchmarkSelect(b *testing.B) {
+	bm := NewBitmap(1 << 20)
+	for i := 0; i < bm.Size(); i += 7 {
+		bm.Set(i)
+	}
+	n := bm.Count()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.Select(i % n)
+	}
+}
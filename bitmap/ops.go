@@ -0,0 +1,130 @@
+package bitmap
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// Set algebra on bitmaps of different sizes treats the shorter operand as zero-extended.
+// In-place operations keep the size of the receiver and ignore bits of the other bitmap
+// past the end of the receiver. Operations returning a new bitmap size the result to the
+// larger of the two operands.
+//
+// Every word is updated atomically, but an operation as a whole is not a snapshot:
+// concurrent writers to either operand may be observed partially.
+
+// Clone returns a copy of the bitmap
+func (b *Bitmap) Clone() *Bitmap {
+	s := b.load()
+	c := NewBitmap(s.size)
+	c.growable = b.growable
+	c.limit = b.limit
+	cs := c.load()
+	for i := range cs.words {
+		cs.words[i] = s.loadWord(i)
+	}
+	return c
+}
+
+// And keeps only the bits that are also set in other
+func (b *Bitmap) And(other *Bitmap) {
+	b.apply(other, func(x, y uint64) uint64 { return x & y })
+}
+
+// Or sets the bits that are set in other
+func (b *Bitmap) Or(other *Bitmap) {
+	b.apply(other, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor flips the bits that are set in other
+func (b *Bitmap) Xor(other *Bitmap) {
+	b.apply(other, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot clears the bits that are set in other
+func (b *Bitmap) AndNot(other *Bitmap) {
+	b.apply(other, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// Not flips every bit of the bitmap
+func (b *Bitmap) Not() {
+	defer b.lockWrite()()
+
+	s := b.load()
+	for i := range s.words {
+		mask := s.wordMask(i)
+		updateWord(&s.words[i], func(x uint64) uint64 { return ^x & mask })
+	}
+}
+
+// AndCount returns the number of bits set in both bitmaps without allocating
+func (b *Bitmap) AndCount(other *Bitmap) int {
+	s, o := b.load(), other.load()
+	n := min(len(s.words), len(o.words))
+	count := 0
+	for i := 0; i < n; i++ {
+		count += bits.OnesCount64(atomic.LoadUint64(&s.words[i]) & atomic.LoadUint64(&o.words[i]))
+	}
+	return count
+}
+
+// OrCount returns the number of bits set in either bitmap without allocating
+func (b *Bitmap) OrCount(other *Bitmap) int {
+	s, o := b.load(), other.load()
+	n := max(len(s.words), len(o.words))
+	count := 0
+	for i := 0; i < n; i++ {
+		count += bits.OnesCount64(s.loadWord(i) | o.loadWord(i))
+	}
+	return count
+}
+
+// And returns a new bitmap holding the bits set in both a and b
+func And(a, b *Bitmap) *Bitmap {
+	return combine(a, b, func(x, y uint64) uint64 { return x & y })
+}
+
+// Or returns a new bitmap holding the bits set in either a or b
+func Or(a, b *Bitmap) *Bitmap {
+	return combine(a, b, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor returns a new bitmap holding the bits set in exactly one of a and b
+func Xor(a, b *Bitmap) *Bitmap {
+	return combine(a, b, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot returns a new bitmap holding the bits set in a but not in b
+func AndNot(a, b *Bitmap) *Bitmap {
+	return combine(a, b, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// Not returns a new bitmap holding the complement of a
+func Not(a *Bitmap) *Bitmap {
+	c := a.Clone()
+	c.Not()
+	return c
+}
+
+// combine builds a new bitmap sized to the larger operand from the word-wise result of op
+func combine(a, b *Bitmap, op func(x, y uint64) uint64) *Bitmap {
+	as, bs := a.load(), b.load()
+	c := NewBitmap(max(as.size, bs.size))
+	cs := c.load()
+	for i := range cs.words {
+		cs.words[i] = op(as.loadWord(i), bs.loadWord(i)) & cs.wordMask(i)
+	}
+	return c
+}
+
+// apply replaces every word of b with op(word, other word)
+func (b *Bitmap) apply(other *Bitmap, op func(x, y uint64) uint64) {
+	defer b.lockWrite()()
+
+	s, o := b.load(), other.load()
+	for i := range s.words {
+		y := o.loadWord(i)
+		mask := s.wordMask(i)
+		updateWord(&s.words[i], func(x uint64) uint64 { return op(x, y) & mask })
+	}
+}
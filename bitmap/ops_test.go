@@ -0,0 +1,148 @@
+package bitmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBitmapWith(size int, setBits ...int) *Bitmap {
+	bm := NewBitmap(size)
+	bm.MSet(setBits)
+	return bm
+}
+
+func setBits(bm *Bitmap) []int {
+	result := make([]int, 0)
+	for i := 0; i < bm.Size(); i++ {
+		if bm.IsSet(i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func TestInPlaceOps(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(a, b *Bitmap)
+		want []int
+	}{
+		{"and", (*Bitmap).And, []int{1, 64}},
+		{"or", (*Bitmap).Or, []int{0, 1, 2, 64, 99}},
+		{"xor", (*Bitmap).Xor, []int{0, 2, 99}},
+		{"and not", (*Bitmap).AndNot, []int{0, 99}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newBitmapWith(100, 0, 1, 64, 99)
+			b := newBitmapWith(100, 1, 2, 64)
+			tt.op(a, b)
+			assert.Equal(t, tt.want, setBits(a))
+			assert.Equal(t, []int{1, 2, 64}, setBits(b), "operand must not change")
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	a := newBitmapWith(70, 0, 69)
+	a.Not()
+	assert.Equal(t, 68, a.Count(), "bits past size must stay clear")
+	assert.False(t, a.IsSet(0))
+	assert.False(t, a.IsSet(69))
+
+	c := Not(a)
+	assert.Equal(t, []int{0, 69}, setBits(c))
+	assert.Equal(t, 68, a.Count())
+}
+
+func TestMismatchedSizes(t *testing.T) {
+	small := newBitmapWith(10, 1, 9)
+	large := newBitmapWith(130, 1, 5, 70, 129)
+
+	t.Run("in place keeps receiver size", func(t *testing.T) {
+		a := small.Clone()
+		a.Or(large)
+		assert.Equal(t, 10, a.Size())
+		assert.Equal(t, []int{1, 5, 9}, setBits(a))
+
+		b := large.Clone()
+		b.And(small)
+		assert.Equal(t, 130, b.Size())
+		assert.Equal(t, []int{1}, setBits(b))
+
+		c := small.Clone()
+		c.Xor(large)
+		assert.Equal(t, []int{5, 9}, setBits(c))
+	})
+
+	t.Run("new result takes larger size", func(t *testing.T) {
+		or := Or(small, large)
+		assert.Equal(t, 130, or.Size())
+		assert.Equal(t, []int{1, 5, 9, 70, 129}, setBits(or))
+
+		and := And(small, large)
+		assert.Equal(t, 130, and.Size())
+		assert.Equal(t, []int{1}, setBits(and))
+
+		xor := Xor(large, small)
+		assert.Equal(t, []int{5, 9, 70, 129}, setBits(xor))
+
+		andNot := AndNot(large, small)
+		assert.Equal(t, []int{5, 70, 129}, setBits(andNot))
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		assert.Equal(t, 1, small.AndCount(large))
+		assert.Equal(t, 1, large.AndCount(small))
+		assert.Equal(t, 5, small.OrCount(large))
+		assert.Equal(t, 5, large.OrCount(small))
+	})
+}
+
+func TestClone(t *testing.T) {
+	a := newBitmapWith(64, 3)
+	c := a.Clone()
+	c.Set(4)
+	assert.Equal(t, []int{3}, setBits(a))
+	assert.Equal(t, []int{3, 4}, setBits(c))
+}
+
+func TestCountsWithoutAllocating(t *testing.T) {
+	a := newBitmapWith(4096, 1, 2, 3)
+	b := newBitmapWith(4096, 2, 3, 4)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = a.AndCount(b)
+		_ = a.OrCount(b)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, 2, a.AndCount(b))
+	assert.Equal(t, 4, a.OrCount(b))
+}
+
+func BenchmarkAnd(b *testing.B) {
+	x := NewBitmap(1 << 20)
+	y := NewBitmap(1 << 20)
+	for i := 0; i < x.Size(); i += 3 {
+		x.Set(i)
+		y.Set(i / 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.And(y)
+	}
+}
+
+func BenchmarkAndCount(b *testing.B) {
+	x := NewBitmap(1 << 20)
+	y := NewBitmap(1 << 20)
+	for i := 0; i < x.Size(); i += 3 {
+		x.Set(i)
+		y.Set(i / 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.AndCount(y)
+	}
+}
@@ -0,0 +1,66 @@
+package bitmap
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SetRange sets the bits in [start, end) to 1.
+// A growable bitmap grows to end, end must not exceed Limit().
+func (b *Bitmap) SetRange(start, end int) {
+	if b.growable && 0 <= start && start < end && end <= b.limit {
+		b.grow(end)
+	}
+	b.updateRange(start, end, func(x, mask uint64) uint64 { return x | mask })
+}
+
+// TrySetRange is like SetRange but returns ErrIndexOutOfRange instead of panicking
+func (b *Bitmap) TrySetRange(start, end int) error {
+	if start < 0 || start > end || end > b.Limit() {
+		return errors.Wrapf(ErrIndexOutOfRange, "range [%d, %d) size %d limit %d", start, end, b.Size(), b.Limit())
+	}
+	b.SetRange(start, end)
+	return nil
+}
+
+// ClearRange clears the bits in [start, end) to 0.
+// On a growable bitmap the part of the range past the end is ignored.
+func (b *Bitmap) ClearRange(start, end int) {
+	if b.growable {
+		end = min(end, max(start, b.Size()))
+	}
+	b.updateRange(start, end, func(x, mask uint64) uint64 { return x &^ mask })
+}
+
+// FlipRange flips the bits in [start, end).
+// A growable bitmap grows to end, end must not exceed Limit().
+func (b *Bitmap) FlipRange(start, end int) {
+	if b.growable && 0 <= start && start < end && end <= b.limit {
+		b.grow(end)
+	}
+	b.updateRange(start, end, func(x, mask uint64) uint64 { return x ^ mask })
+}
+
+// updateRange applies op to every word overlapping [start, end) with the mask of the bits in range
+func (b *Bitmap) updateRange(start, end int, op func(x, mask uint64) uint64) {
+	defer b.lockWrite()()
+
+	s := b.load()
+	if start < 0 || end > s.size || start > end {
+		panic("bitmap range out of range")
+	}
+	if start == end {
+		return
+	}
+
+	first, last := start>>wordShift, (end-1)>>wordShift
+	for w := first; w <= last; w++ {
+		mask := ^uint64(0)
+		if w == first {
+			mask &= ^uint64(0) << (start & wordMask)
+		}
+		if w == last {
+			mask &= ^uint64(0) >> (wordMask - (end-1)&wordMask)
+		}
+		updateWord(&s.words[w], func(x uint64) uint64 { return op(x, mask) })
+	}
+}
@@ -0,0 +1,98 @@
+package bitmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeOps(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+	}{
+		{"empty", 5, 5},
+		{"within word", 3, 9},
+		{"word boundary", 0, 64},
+		{"cross words", 60, 130},
+		{"to end", 100, 200},
+		{"whole bitmap", 0, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := NewBitmap(200)
+			bm.SetRange(tt.start, tt.end)
+			assert.Equal(t, tt.end-tt.start, bm.Count())
+			for i := 0; i < bm.Size(); i++ {
+				assert.Equalf(t, i >= tt.start && i < tt.end, bm.IsSet(i), "bit %d", i)
+			}
+
+			bm.ClearRange(tt.start, tt.end)
+			assert.Equal(t, 0, bm.Count())
+
+			bm.Set(tt.start)
+			bm.FlipRange(tt.start, tt.end)
+			for i := 0; i < bm.Size(); i++ {
+				want := i > tt.start && i < tt.end
+				if tt.start == tt.end {
+					want = i == tt.start
+				}
+				assert.Equalf(t, want, bm.IsSet(i), "bit %d", i)
+			}
+		})
+	}
+
+	t.Run("invalid ranges panic", func(t *testing.T) {
+		bm := NewBitmap(10)
+		assert.Panics(t, func() { bm.SetRange(-1, 5) })
+		assert.Panics(t, func() { bm.SetRange(5, 11) })
+		assert.Panics(t, func() { bm.ClearRange(6, 5) })
+		assert.Panics(t, func() { bm.FlipRange(0, 11) })
+	})
+}
+
+func TestGrowableRangeOps(t *testing.T) {
+	bm := NewGrowableBitmap(0, 200)
+	bm.SetRange(10, 100)
+	assert.Equal(t, 100, bm.Size())
+	assert.Equal(t, 90, bm.Count())
+
+	bm.ClearRange(50, 1000)
+	assert.Equal(t, 100, bm.Size(), "clearing past the end must not grow")
+	assert.Equal(t, 40, bm.Count())
+
+	bm.FlipRange(0, 150)
+	assert.Equal(t, 150, bm.Size())
+	assert.Equal(t, 110, bm.Count())
+	assert.False(t, bm.IsSet(10))
+	assert.True(t, bm.IsSet(149))
+
+	assert.NoError(t, bm.TrySetRange(150, 200))
+	assert.Equal(t, 200, bm.Size())
+	assert.ErrorIs(t, bm.TrySetRange(150, 201), ErrIndexOutOfRange)
+	assert.ErrorIs(t, bm.TrySetRange(0, 1<<40), ErrIndexOutOfRange)
+	assert.Panics(t, func() { bm.SetRange(0, 1<<40) })
+	assert.Panics(t, func() { bm.FlipRange(0, 1<<40) })
+	assert.Equal(t, 200, bm.Size(), "ranges past the limit must not grow the bitmap")
+}
+
+func TestTrySetRange(t *testing.T) {
+	bm := NewBitmap(100)
+	assert.NoError(t, bm.TrySetRange(10, 20))
+	assert.NoError(t, bm.TrySetRange(5, 5))
+	assert.Equal(t, 10, bm.Count())
+
+	for _, r := range [][2]int{{-1, 5}, {20, 10}, {90, 101}} {
+		assert.ErrorIs(t, bm.TrySetRange(r[0], r[1]), ErrIndexOutOfRange, "%v", r)
+	}
+	assert.Equal(t, 10, bm.Count())
+}
+
+func BenchmarkSetRange(b *testing.B) {
+	bm := NewBitmap(1 << 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.SetRange(13, bm.Size()-13)
+	}
+}
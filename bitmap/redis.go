@@ -0,0 +1,225 @@
+package bitmap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultRedisTimeout = time.Second
+	MaxRedisBitmapSize  = 1 << 32 // Redis strings are limited to 512MB
+)
+
+var _ Interface = (*RedisBitmap)(nil)
+var _ AllSetter = (*RedisBitmap)(nil)
+
+// RedisBitmap represents a bitmap stored in a Redis string, shared by every process using the same key.
+// Bit i is the bit at offset i of SETBIT/GETBIT, the same order as Bitmap.Bytes and FromBytes.
+//
+// The Interface methods run with the timeout given at construction and have no error result,
+// a failed call is recorded for Err. Failed IsSet and AllSet report the bits as set, so that a filter
+// on the bitmap answers "maybe present" rather than a false "absent" while Redis is unavailable,
+// and a failed Count reports 0. Use the Context methods to handle errors per call.
+type RedisBitmap struct {
+	rdb     redis.Cmdable
+	key     string
+	size    int
+	timeout time.Duration
+
+	mutex sync.Mutex
+	err   error
+}
+
+// NewRedisBitmap creates a bitmap of the given size (in bits) stored under key.
+// rdb is usually the cache.Cacheable returned by cache.NewRedis or cache.NewRedisCluster.
+// timeout bounds every call of the Interface methods, DefaultRedisTimeout is used if it is not positive.
+func NewRedisBitmap(rdb redis.Cmdable, key string, size int, timeout time.Duration) *RedisBitmap {
+	if size < 0 || size > MaxRedisBitmapSize {
+		panic("redis bitmap size out of range")
+	}
+	if timeout <= 0 {
+		timeout = DefaultRedisTimeout
+	}
+	return &RedisBitmap{
+		rdb:     rdb,
+		key:     key,
+		size:    size,
+		timeout: timeout,
+	}
+}
+
+// Set sets the bit at the given index to 1
+func (b *RedisBitmap) Set(index int) {
+	ctx, cancel := b.context()
+	defer cancel()
+	b.record(b.SetContext(ctx, index))
+}
+
+// MSet sets the bits at all given indexes to 1 in a single round trip
+func (b *RedisBitmap) MSet(indexes []int) {
+	ctx, cancel := b.context()
+	defer cancel()
+	b.record(b.MSetContext(ctx, indexes))
+}
+
+// Clear clears the bit at the given index to 0
+func (b *RedisBitmap) Clear(index int) {
+	ctx, cancel := b.context()
+	defer cancel()
+	b.record(b.ClearContext(ctx, index))
+}
+
+// IsSet checks if the bit at the given index is set to 1, it reports true if Redis fails
+func (b *RedisBitmap) IsSet(index int) bool {
+	ctx, cancel := b.context()
+	defer cancel()
+	ok, err := b.IsSetContext(ctx, index)
+	b.record(err)
+	return ok || err != nil
+}
+
+// AllSet checks if the bits at all given indexes are set to 1 in a single round trip,
+// it reports true if Redis fails
+func (b *RedisBitmap) AllSet(indexes []int) bool {
+	ctx, cancel := b.context()
+	defer cancel()
+	ok, err := b.AllSetContext(ctx, indexes)
+	b.record(err)
+	return ok || err != nil
+}
+
+// Count returns the number of bits set to 1
+func (b *RedisBitmap) Count() int {
+	ctx, cancel := b.context()
+	defer cancel()
+	count, err := b.CountContext(ctx)
+	b.record(err)
+	return count
+}
+
+// Size returns the capacity of the bitmap in bits
+func (b *RedisBitmap) Size() int {
+	return b.size
+}
+
+// Key returns the Redis key of the bitmap
+func (b *RedisBitmap) Key() string {
+	return b.key
+}
+
+// Err returns the last error of the Interface methods and resets it
+func (b *RedisBitmap) Err() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// SetContext sets the bit at the given index to 1
+func (b *RedisBitmap) SetContext(ctx context.Context, index int) error {
+	b.validateIndex(index)
+	if err := b.rdb.SetBit(ctx, b.key, int64(index), 1).Err(); err != nil {
+		return errors.Wrapf(err, "redis bitmap setbit failed. key:%s index:%d", b.key, index)
+	}
+	return nil
+}
+
+// MSetContext sets the bits at all given indexes to 1 in a single pipeline
+func (b *RedisBitmap) MSetContext(ctx context.Context, indexes []int) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	for _, index := range indexes {
+		b.validateIndex(index)
+	}
+
+	_, err := b.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, index := range indexes {
+			pipe.SetBit(ctx, b.key, int64(index), 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "redis bitmap pipelined setbit failed. key:%s", b.key)
+	}
+	return nil
+}
+
+// ClearContext clears the bit at the given index to 0
+func (b *RedisBitmap) ClearContext(ctx context.Context, index int) error {
+	b.validateIndex(index)
+	if err := b.rdb.SetBit(ctx, b.key, int64(index), 0).Err(); err != nil {
+		return errors.Wrapf(err, "redis bitmap setbit failed. key:%s index:%d", b.key, index)
+	}
+	return nil
+}
+
+// IsSetContext checks if the bit at the given index is set to 1
+func (b *RedisBitmap) IsSetContext(ctx context.Context, index int) (bool, error) {
+	b.validateIndex(index)
+	v, err := b.rdb.GetBit(ctx, b.key, int64(index)).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "redis bitmap getbit failed. key:%s index:%d", b.key, index)
+	}
+	return v == 1, nil
+}
+
+// AllSetContext checks if the bits at all given indexes are set to 1 in a single pipeline
+func (b *RedisBitmap) AllSetContext(ctx context.Context, indexes []int) (bool, error) {
+	if len(indexes) == 0 {
+		return true, nil
+	}
+	for _, index := range indexes {
+		b.validateIndex(index)
+	}
+
+	cmds, err := b.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, index := range indexes {
+			pipe.GetBit(ctx, b.key, int64(index))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "redis bitmap pipelined getbit failed. key:%s", b.key)
+	}
+	for _, cmd := range cmds {
+		if cmd.(*redis.IntCmd).Val() != 1 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CountContext returns the number of bits set to 1
+func (b *RedisBitmap) CountContext(ctx context.Context) (int, error) {
+	count, err := b.rdb.BitCount(ctx, b.key, nil).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "redis bitmap bitcount failed. key:%s", b.key)
+	}
+	return int(count), nil
+}
+
+func (b *RedisBitmap) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+func (b *RedisBitmap) record(err error) {
+	if err == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.err = err
+}
+
+// validateIndex checks if index is within valid range
+func (b *RedisBitmap) validateIndex(index int) {
+	if index < 0 || index >= b.size {
+		panic("bitmap index out of range")
+	}
+}
@@ -0,0 +1,115 @@
+package bitmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.Cmdable) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, rdb
+}
+
+func TestRedisBitmap(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	bm := NewRedisBitmap(rdb, "test:bitmap", 100, 0)
+	assert.Equal(t, 100, bm.Size())
+	assert.Equal(t, "test:bitmap", bm.Key())
+
+	assert.False(t, bm.IsSet(5))
+	bm.Set(5)
+	bm.MSet([]int{0, 63, 99, 5})
+	assert.True(t, bm.IsSet(5))
+	assert.True(t, bm.IsSet(99))
+	assert.False(t, bm.IsSet(1))
+	assert.Equal(t, 4, bm.Count())
+
+	assert.True(t, bm.AllSet([]int{0, 5, 63}))
+	assert.False(t, bm.AllSet([]int{0, 5, 64}))
+	assert.True(t, bm.AllSet(nil))
+
+	bm.Clear(5)
+	assert.False(t, bm.IsSet(5))
+	assert.Equal(t, 3, bm.Count())
+	assert.NoError(t, bm.Err())
+
+	assert.Panics(t, func() { bm.Set(100) })
+	assert.Panics(t, func() { bm.MSet([]int{1, -1}) })
+	assert.Panics(t, func() { NewRedisBitmap(rdb, "k", -1, 0) })
+}
+
+func TestRedisBitmapSharesBitOrder(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	rbm := NewRedisBitmap(rdb, "test:order", 16, 0)
+	rbm.MSet([]int{0, 7, 9})
+
+	// a bitmap written by SETBIT loads without bit reversal
+	data, err := rdb.Get(ctx, "test:order").Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 7, 9}, setBits(FromBytes(data)))
+
+	// and the other way round
+	local := newBitmapWith(16, 3, 15)
+	require.NoError(t, rdb.Set(ctx, "test:order", local.Bytes(), 0).Err())
+	assert.True(t, rbm.IsSet(3))
+	assert.True(t, rbm.IsSet(15))
+	assert.False(t, rbm.IsSet(0))
+}
+
+func TestRedisBitmapErrors(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	bm := NewRedisBitmap(rdb, "test:errors", 10, 0)
+	bm.Set(1)
+	mr.Close()
+
+	assert.True(t, bm.IsSet(5), "failed reads report the bit as set")
+	assert.Error(t, bm.Err())
+	assert.NoError(t, bm.Err(), "Err resets the error")
+
+	bm.MSet([]int{2, 3})
+	assert.Error(t, bm.Err())
+
+	ctx := context.Background()
+	assert.Error(t, bm.SetContext(ctx, 1))
+	_, err := bm.CountContext(ctx)
+	assert.Error(t, err)
+	_, err = bm.AllSetContext(ctx, []int{1})
+	assert.Error(t, err)
+}
+
+func TestRedisBitmapReadErrors(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	bm := NewRedisBitmap(rdb, "test:read-errors", 10, 0)
+	bm.Set(1)
+	require.NoError(t, bm.Err())
+
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+	assert.True(t, bm.IsSet(2), "a failed read must not report an unset bit")
+	assert.ErrorContains(t, bm.Err(), "LOADING")
+	assert.True(t, bm.AllSet([]int{2, 3}))
+	assert.Error(t, bm.Err())
+	assert.Zero(t, bm.Count())
+	assert.Error(t, bm.Err())
+
+	ctx := context.Background()
+	_, err := bm.IsSetContext(ctx, 2)
+	assert.Error(t, err, "the Context methods return the error")
+	ok, err := bm.AllSetContext(ctx, []int{1})
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	mr.SetError("")
+	assert.False(t, bm.IsSet(2))
+	assert.False(t, bm.AllSet([]int{1, 2}))
+	assert.True(t, bm.AllSet([]int{1}))
+	assert.NoError(t, bm.Err())
+}
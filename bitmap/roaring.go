@@ -0,0 +1,388 @@
+package bitmap
+
+import (
+	"encoding"
+	"encoding/binary"
+	"iter"
+	"maps"
+	"math/bits"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Roaring represents a thread-safe compressed bitmap over 64-bit values.
+// Values are grouped by their high 48 bits, and the low 16 bits of every group are kept
+// in the smallest of an array, a bitmap or a run container, so sparse and clustered
+// sets such as player IDs cost a few bytes per value instead of one bit per possible value.
+type Roaring struct {
+	mutex      sync.RWMutex
+	containers map[uint64]container // keyed by the high 48 bits of the values
+}
+
+// NewRoaring creates a new empty Roaring bitmap
+func NewRoaring() *Roaring {
+	return &Roaring{containers: make(map[uint64]container)}
+}
+
+// Set sets the bit at the given index to 1
+func (r *Roaring) Set(index int) {
+	r.Set64(toUint64(index))
+}
+
+// Clear clears the bit at the given index to 0
+func (r *Roaring) Clear(index int) {
+	r.Clear64(toUint64(index))
+}
+
+// IsSet checks if the bit at the given index is set to 1
+func (r *Roaring) IsSet(index int) bool {
+	return r.IsSet64(toUint64(index))
+}
+
+// Set64 sets the bit at the given 64-bit index to 1
+func (r *Roaring) Set64(x uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key, low := x>>16, uint16(x)
+	if c, ok := r.containers[key]; ok {
+		r.containers[key] = c.add(low)
+	} else {
+		r.containers[key] = arrayContainer{low}
+	}
+}
+
+// Clear64 clears the bit at the given 64-bit index to 0
+func (r *Roaring) Clear64(x uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := x >> 16
+	if c, ok := r.containers[key]; ok {
+		r.setContainer(key, c.remove(uint16(x)))
+	}
+}
+
+// IsSet64 checks if the bit at the given 64-bit index is set to 1
+func (r *Roaring) IsSet64(x uint64) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	c, ok := r.containers[x>>16]
+	return ok && c.contains(uint16(x))
+}
+
+// Count returns the number of bits set to 1
+func (r *Roaring) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	count := 0
+	for _, c := range r.containers {
+		count += c.cardinality()
+	}
+	return count
+}
+
+// SetBits returns an iterator over the bits set to 1 in ascending order.
+// The bitmap is read-locked while iterating, so it must not be modified from the loop body.
+func (r *Roaring) SetBits() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+
+		for _, key := range r.sortedKeys() {
+			high := key << 16
+			if !r.containers[key].iterate(func(v uint16) bool { return yield(high | uint64(v)) }) {
+				return
+			}
+		}
+	}
+}
+
+// RunOptimize converts every container to its smallest representation,
+// which compresses long runs of consecutive values. Call it after bulk loading.
+func (r *Roaring) RunOptimize() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, c := range r.containers {
+		r.containers[key] = optimize(c)
+	}
+}
+
+// Clone returns a copy of the bitmap
+func (r *Roaring) Clone() *Roaring {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	c := &Roaring{containers: make(map[uint64]container, len(r.containers))}
+	for key, ct := range r.containers {
+		c.containers[key] = ct.clone()
+	}
+	return c
+}
+
+// And keeps only the bits that are also set in other
+func (r *Roaring) And(other *Roaring) {
+	r.merge(other, containerAnd, false, false)
+}
+
+// Or sets the bits that are set in other
+func (r *Roaring) Or(other *Roaring) {
+	r.merge(other, containerOr, true, true)
+}
+
+// Xor flips the bits that are set in other
+func (r *Roaring) Xor(other *Roaring) {
+	r.merge(other, containerXor, true, true)
+}
+
+// AndNot clears the bits that are set in other
+func (r *Roaring) AndNot(other *Roaring) {
+	r.merge(other, containerAndNot, true, false)
+}
+
+// merge combines the containers of r and other with the same key using op.
+// keepOwn and keepOther tell whether containers without a counterpart survive.
+func (r *Roaring) merge(other *Roaring, op func(a, b container) container, keepOwn, keepOther bool) {
+	// work on a copy of other so that the two bitmaps are never locked at the same time
+	o := other.Clone()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, oc := range o.containers {
+		if c, ok := r.containers[key]; ok {
+			r.setContainer(key, op(c, oc))
+		} else if keepOther {
+			r.containers[key] = oc
+		}
+	}
+	if !keepOwn {
+		for key := range r.containers {
+			if _, ok := o.containers[key]; !ok {
+				delete(r.containers, key)
+			}
+		}
+	}
+}
+
+// setContainer stores the container under key, dropping it when it is empty
+func (r *Roaring) setContainer(key uint64, c container) {
+	if c.cardinality() > 0 {
+		r.containers[key] = c
+	} else {
+		delete(r.containers, key)
+	}
+}
+
+// sortedKeys returns the container keys in ascending order
+func (r *Roaring) sortedKeys() []uint64 {
+	return slices.Sorted(maps.Keys(r.containers))
+}
+
+// Binary format of a marshaled Roaring:
+//
+//	version     1 byte
+//	containers  uvarint
+//	for every container, in ascending key order:
+//	  key       uvarint  high 48 bits of the values
+//	  kind      1 byte   see containerArray, containerBitmap and containerRun
+//	  array:    uvarint cardinality, then the values as little endian uint16
+//	  bitmap:   1024 little endian uint64 words
+//	  run:      uvarint number of runs, then start and length-1 of each run as little endian uint16
+const (
+	roaringEncodingVersion = 1
+
+	containerArray  = 1
+	containerBitmap = 2
+	containerRun    = 3
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Roaring)(nil)
+	_ encoding.BinaryUnmarshaler = (*Roaring)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Containers are written as they are, call RunOptimize first for the smallest output.
+func (r *Roaring) MarshalBinary() ([]byte, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	data := []byte{roaringEncodingVersion}
+	data = binary.AppendUvarint(data, uint64(len(r.containers)))
+	for _, key := range r.sortedKeys() {
+		data = binary.AppendUvarint(data, key)
+		switch c := r.containers[key].(type) {
+		case arrayContainer:
+			data = append(data, containerArray)
+			data = binary.AppendUvarint(data, uint64(len(c)))
+			for _, v := range c {
+				data = binary.LittleEndian.AppendUint16(data, v)
+			}
+		case *bitmapContainer:
+			data = append(data, containerBitmap)
+			for _, w := range c.words {
+				data = binary.LittleEndian.AppendUint64(data, w)
+			}
+		case runContainer:
+			data = append(data, containerRun)
+			data = binary.AppendUvarint(data, uint64(len(c)))
+			for _, iv := range c {
+				data = binary.LittleEndian.AppendUint16(data, iv.start)
+				data = binary.LittleEndian.AppendUint16(data, iv.last-iv.start)
+			}
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the bitmap
+func (r *Roaring) UnmarshalBinary(data []byte) error {
+	d := decoder{data: data}
+	if version := d.byte(); d.err == nil && version != roaringEncodingVersion {
+		return errors.Wrapf(ErrInvalidEncoding, "unsupported roaring version %d", version)
+	}
+
+	n := d.uvarint()
+	if d.err == nil && n > uint64(len(data)) {
+		return errors.Wrap(ErrInvalidEncoding, "invalid container count")
+	}
+	containers := make(map[uint64]container, n)
+	var prev uint64
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := d.uvarint()
+		if (i > 0 && key <= prev) || key >= 1<<48 {
+			d.fail("keys out of order")
+		}
+		if c := d.container(); d.err == nil {
+			containers[key] = c
+		}
+		prev = key
+	}
+	if d.err == nil && len(d.data) != 0 {
+		d.fail("trailing data")
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.containers = containers
+	return nil
+}
+
+// decoder reads the binary format of Roaring, remembering the first error
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail(msg string) {
+	if d.err == nil {
+		d.err = errors.Wrap(ErrInvalidEncoding, msg)
+	}
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil || n > len(d.data) {
+		d.fail("unexpected end of data")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	if b := d.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.take(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail("invalid uvarint")
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) container() container {
+	switch kind := d.byte(); kind {
+	case containerArray:
+		n := d.uvarint()
+		if n == 0 || n > arrayMaxSize {
+			d.fail("invalid array container size")
+			return nil
+		}
+		a := make(arrayContainer, n)
+		for i := range a {
+			a[i] = d.uint16()
+			if i > 0 && a[i] <= a[i-1] {
+				d.fail("array container out of order")
+			}
+		}
+		return a
+	case containerBitmap:
+		b := &bitmapContainer{}
+		for i := range b.words {
+			if w := d.take(8); w != nil {
+				b.words[i] = binary.LittleEndian.Uint64(w)
+			}
+		}
+		for _, w := range b.words {
+			b.card += bits.OnesCount64(w)
+		}
+		if b.card == 0 {
+			d.fail("empty bitmap container")
+		}
+		return b
+	case containerRun:
+		n := d.uvarint()
+		if n == 0 || n > 1<<15 {
+			d.fail("invalid run container size")
+			return nil
+		}
+		r := make(runContainer, n)
+		for i := range r {
+			start, length := d.uint16(), d.uint16()
+			if int(start)+int(length) > 0xffff || (i > 0 && int(start) <= int(r[i-1].last)+1) {
+				d.fail("invalid run")
+			}
+			r[i] = interval{start: start, last: start + length}
+		}
+		return r
+	default:
+		if d.err == nil {
+			d.fail("unknown container kind")
+		}
+		return nil
+	}
+}
+
+// toUint64 converts a non-negative index to a 64-bit value
+func toUint64(index int) uint64 {
+	if index < 0 {
+		panic("bitmap index out of range")
+	}
+	return uint64(index)
+}
@@ -0,0 +1,415 @@
+package bitmap
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// Containers hold the low 16 bits of the values that share the same high bits.
+// A container is never empty, the Roaring owning it drops it instead.
+const (
+	arrayMaxSize   = 4096               // an array container larger than this becomes a bitmap container
+	containerWords = 1 << 16 / wordBits // words of a bitmap container
+
+	bitmapContainerBytes = containerWords * 8
+)
+
+type container interface {
+	// add returns the container holding x, which may be a converted container
+	add(x uint16) container
+	// remove returns the container without x, which may be a converted container
+	remove(x uint16) container
+	contains(x uint16) bool
+	cardinality() int
+	// iterate calls yield for every value in ascending order, returns false if yield stopped it
+	iterate(yield func(uint16) bool) bool
+	// toBitmap returns the container as a bitmap container, which must not be modified
+	toBitmap() *bitmapContainer
+	// runs returns the number of runs of consecutive values
+	runs() int
+	clone() container
+}
+
+// arrayContainer is a sorted list of values, used for sparse containers
+type arrayContainer []uint16
+
+func (a arrayContainer) add(x uint16) container {
+	i, found := slices.BinarySearch(a, x)
+	if found {
+		return a
+	}
+	if len(a) >= arrayMaxSize {
+		return a.toBitmap().add(x)
+	}
+	return slices.Insert(a, i, x)
+}
+
+func (a arrayContainer) remove(x uint16) container {
+	i, found := slices.BinarySearch(a, x)
+	if !found {
+		return a
+	}
+	return slices.Delete(a, i, i+1)
+}
+
+func (a arrayContainer) contains(x uint16) bool {
+	_, found := slices.BinarySearch(a, x)
+	return found
+}
+
+func (a arrayContainer) cardinality() int {
+	return len(a)
+}
+
+func (a arrayContainer) iterate(yield func(uint16) bool) bool {
+	for _, v := range a {
+		if !yield(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{card: len(a)}
+	for _, v := range a {
+		b.words[v>>wordShift] |= 1 << (v & wordMask)
+	}
+	return b
+}
+
+func (a arrayContainer) runs() int {
+	n := 0
+	for i, v := range a {
+		if i == 0 || a[i-1]+1 != v {
+			n++
+		}
+	}
+	return n
+}
+
+func (a arrayContainer) clone() container {
+	return slices.Clone(a)
+}
+
+// filter returns a new array container with the values for which keep returns true
+func (a arrayContainer) filter(keep func(uint16) bool) arrayContainer {
+	result := make(arrayContainer, 0, len(a))
+	for _, v := range a {
+		if keep(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// bitmapContainer is a fixed 2^16 bit bitmap, used for dense containers
+type bitmapContainer struct {
+	words [containerWords]uint64
+	card  int
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	w, mask := x>>wordShift, uint64(1)<<(x&wordMask)
+	if b.words[w]&mask == 0 {
+		b.words[w] |= mask
+		b.card++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	w, mask := x>>wordShift, uint64(1)<<(x&wordMask)
+	if b.words[w]&mask == 0 {
+		return b
+	}
+	b.words[w] &^= mask
+	b.card--
+	return b.normalize()
+}
+
+func (b *bitmapContainer) contains(x uint16) bool {
+	return b.words[x>>wordShift]&(1<<(x&wordMask)) != 0
+}
+
+func (b *bitmapContainer) cardinality() int {
+	return b.card
+}
+
+func (b *bitmapContainer) iterate(yield func(uint16) bool) bool {
+	for w, word := range b.words {
+		for word != 0 {
+			if !yield(uint16(w<<wordShift + bits.TrailingZeros64(word))) {
+				return false
+			}
+			word &= word - 1
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) toBitmap() *bitmapContainer {
+	return b
+}
+
+func (b *bitmapContainer) runs() int {
+	n := 0
+	var carry uint64
+	for _, word := range b.words {
+		// a run starts at every set bit whose lower neighbour is clear
+		n += bits.OnesCount64(word &^ (word<<1 | carry))
+		carry = word >> wordMask
+	}
+	return n
+}
+
+func (b *bitmapContainer) clone() container {
+	c := *b
+	return &c
+}
+
+// normalize returns the container as an array container when it is sparse enough
+func (b *bitmapContainer) normalize() container {
+	if b.card > arrayMaxSize {
+		return b
+	}
+	a := make(arrayContainer, 0, b.card)
+	b.iterate(func(v uint16) bool {
+		a = append(a, v)
+		return true
+	})
+	return a
+}
+
+// interval is an inclusive range of values
+type interval struct {
+	start, last uint16
+}
+
+// runContainer is a sorted list of non-adjacent intervals, used for containers of consecutive values.
+// Run containers are only created by Roaring.RunOptimize and decoding.
+type runContainer []interval
+
+// search returns the index of the first interval ending at or after x
+func (r runContainer) search(x uint16) int {
+	return sort.Search(len(r), func(i int) bool { return r[i].last >= x })
+}
+
+func (r runContainer) add(x uint16) container {
+	i := r.search(x)
+	if i < len(r) && r[i].start <= x {
+		return r
+	}
+
+	joinNext := i < len(r) && int(r[i].start) == int(x)+1
+	joinPrev := i > 0 && int(r[i-1].last)+1 == int(x)
+	switch {
+	case joinPrev && joinNext:
+		r[i-1].last = r[i].last
+		return slices.Delete(r, i, i+1)
+	case joinPrev:
+		r[i-1].last = x
+	case joinNext:
+		r[i].start = x
+	default:
+		return slices.Insert(r, i, interval{start: x, last: x})
+	}
+	return r
+}
+
+func (r runContainer) remove(x uint16) container {
+	i := r.search(x)
+	if i == len(r) || r[i].start > x {
+		return r
+	}
+
+	iv := r[i]
+	switch {
+	case iv.start == iv.last:
+		return slices.Delete(r, i, i+1)
+	case x == iv.start:
+		r[i].start++
+	case x == iv.last:
+		r[i].last--
+	default:
+		r[i].last = x - 1
+		return slices.Insert(r, i+1, interval{start: x + 1, last: iv.last})
+	}
+	return r
+}
+
+func (r runContainer) contains(x uint16) bool {
+	i := r.search(x)
+	return i < len(r) && r[i].start <= x
+}
+
+func (r runContainer) cardinality() int {
+	n := 0
+	for _, iv := range r {
+		n += int(iv.last-iv.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) iterate(yield func(uint16) bool) bool {
+	for _, iv := range r {
+		for v := int(iv.start); v <= int(iv.last); v++ {
+			if !yield(uint16(v)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{card: r.cardinality()}
+	for _, iv := range r {
+		start, end := int(iv.start), int(iv.last)+1
+		first, last := start>>wordShift, (end-1)>>wordShift
+		for w := first; w <= last; w++ {
+			mask := ^uint64(0)
+			if w == first {
+				mask &= ^uint64(0) << (start & wordMask)
+			}
+			if w == last {
+				mask &= ^uint64(0) >> (wordMask - (end-1)&wordMask)
+			}
+			b.words[w] |= mask
+		}
+	}
+	return b
+}
+
+func (r runContainer) runs() int {
+	return len(r)
+}
+
+func (r runContainer) clone() container {
+	return slices.Clone(r)
+}
+
+// optimize returns the smallest representation of the container
+func optimize(c container) container {
+	card, runs := c.cardinality(), c.runs()
+	arrayBytes, runBytes := 2*card, 4*runs
+	switch {
+	case runBytes < arrayBytes && runBytes < bitmapContainerBytes:
+		if r, ok := c.(runContainer); ok {
+			return r
+		}
+		r := make(runContainer, 0, runs)
+		c.iterate(func(v uint16) bool {
+			if n := len(r); n > 0 && int(r[n-1].last)+1 == int(v) {
+				r[n-1].last = v
+			} else {
+				r = append(r, interval{start: v, last: v})
+			}
+			return true
+		})
+		return r
+	case card <= arrayMaxSize:
+		if a, ok := c.(arrayContainer); ok {
+			return a
+		}
+		return c.toBitmap().normalize()
+	default:
+		if b, ok := c.(*bitmapContainer); ok {
+			return b
+		}
+		return c.toBitmap()
+	}
+}
+
+// containerAnd returns the values in both a and b
+func containerAnd(a, b container) container {
+	aa, aIsArray := a.(arrayContainer)
+	ba, bIsArray := b.(arrayContainer)
+	switch {
+	case aIsArray && bIsArray:
+		result := make(arrayContainer, 0, min(len(aa), len(ba)))
+		for i, j := 0, 0; i < len(aa) && j < len(ba); {
+			switch {
+			case aa[i] < ba[j]:
+				i++
+			case aa[i] > ba[j]:
+				j++
+			default:
+				result = append(result, aa[i])
+				i++
+				j++
+			}
+		}
+		return result
+	case aIsArray:
+		return aa.filter(b.contains)
+	case bIsArray:
+		return ba.filter(a.contains)
+	default:
+		return bitmapOp(a, b, func(x, y uint64) uint64 { return x & y })
+	}
+}
+
+// containerOr returns the values in a or b
+func containerOr(a, b container) container {
+	aa, aIsArray := a.(arrayContainer)
+	ba, bIsArray := b.(arrayContainer)
+	if aIsArray && bIsArray && len(aa)+len(ba) <= arrayMaxSize {
+		return mergeArrays(aa, ba, true)
+	}
+	return bitmapOp(a, b, func(x, y uint64) uint64 { return x | y })
+}
+
+// containerXor returns the values in exactly one of a and b
+func containerXor(a, b container) container {
+	aa, aIsArray := a.(arrayContainer)
+	ba, bIsArray := b.(arrayContainer)
+	if aIsArray && bIsArray && len(aa)+len(ba) <= arrayMaxSize {
+		return mergeArrays(aa, ba, false)
+	}
+	return bitmapOp(a, b, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// containerAndNot returns the values in a but not in b
+func containerAndNot(a, b container) container {
+	if aa, ok := a.(arrayContainer); ok {
+		return aa.filter(func(v uint16) bool { return !b.contains(v) })
+	}
+	return bitmapOp(a, b, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// mergeArrays merges two sorted arrays, keeping values present in both only if union is true
+func mergeArrays(a, b arrayContainer, union bool) arrayContainer {
+	result := make(arrayContainer, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			if union {
+				result = append(result, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// bitmapOp combines two containers word by word
+func bitmapOp(a, b container, op func(x, y uint64) uint64) container {
+	ab, bb := a.toBitmap(), b.toBitmap()
+	result := &bitmapContainer{}
+	for i := range result.words {
+		result.words[i] = op(ab.words[i], bb.words[i])
+		result.card += bits.OnesCount64(result.words[i])
+	}
+	return result.normalize()
+}
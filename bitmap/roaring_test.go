@@ -0,0 +1,308 @@
+package bitmap
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modelValues returns the sorted keys of a set model
+func modelValues(m map[uint64]bool) []uint64 {
+	values := make([]uint64, 0, len(m))
+	for v := range m {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+func newRoaringWith(values ...uint64) *Roaring {
+	r := NewRoaring()
+	for _, v := range values {
+		r.Set64(v)
+	}
+	return r
+}
+
+func TestRoaringBasic(t *testing.T) {
+	r := NewRoaring()
+	assert.Equal(t, 0, r.Count())
+	assert.False(t, r.IsSet(0))
+
+	r.Set(0)
+	r.Set(65535)
+	r.Set(65536)
+	r.Set64(1<<63 + 5)
+	r.Set64(^uint64(0))
+	r.Set(0)
+
+	assert.Equal(t, 5, r.Count())
+	assert.True(t, r.IsSet(0))
+	assert.True(t, r.IsSet(65536))
+	assert.True(t, r.IsSet64(1<<63+5))
+	assert.True(t, r.IsSet64(^uint64(0)))
+	assert.False(t, r.IsSet(1))
+	assert.Equal(t, []uint64{0, 65535, 65536, 1<<63 + 5, ^uint64(0)}, slices.Collect(r.SetBits()))
+
+	r.Clear(65536)
+	r.Clear(12345) // Shouldn't panic
+	assert.False(t, r.IsSet(65536))
+	assert.Equal(t, 4, r.Count())
+	assert.Len(t, r.containers, 3, "empty containers are dropped")
+
+	assert.Panics(t, func() { r.Set(-1) })
+}
+
+func TestRoaringContainerConversions(t *testing.T) {
+	r := NewRoaring()
+	for i := 0; i < arrayMaxSize; i++ {
+		r.Set(i * 2)
+	}
+	assert.IsType(t, arrayContainer{}, r.containers[0])
+
+	r.Set(1)
+	assert.IsType(t, &bitmapContainer{}, r.containers[0])
+	assert.Equal(t, arrayMaxSize+1, r.Count())
+
+	r.Clear(1)
+	assert.IsType(t, arrayContainer{}, r.containers[0])
+	assert.Equal(t, arrayMaxSize, r.Count())
+
+	t.Run("run optimize", func(t *testing.T) {
+		r := NewRoaring()
+		for i := 1000; i < 60000; i++ {
+			r.Set(i)
+		}
+		assert.IsType(t, &bitmapContainer{}, r.containers[0])
+		r.RunOptimize()
+		assert.Equal(t, runContainer{{1000, 59999}}, r.containers[0])
+		assert.Equal(t, 59000, r.Count())
+
+		// run containers stay correct under modification
+		r.Clear(1000)
+		r.Clear(59999)
+		r.Clear(30000)
+		r.Set(999)
+		r.Set(30000)
+		r.Set(70000)
+		assert.Equal(t, runContainer{{999, 999}, {1001, 59998}}, r.containers[0])
+		assert.True(t, r.IsSet(30000))
+		assert.True(t, r.IsSet(70000))
+		assert.Equal(t, 59000, r.Count())
+
+		r.Set(1000)
+		assert.Equal(t, runContainer{{999, 59998}}, r.containers[0])
+	})
+
+	t.Run("sparse stays array", func(t *testing.T) {
+		r := newRoaringWith(1, 100, 10000)
+		r.RunOptimize()
+		assert.Equal(t, arrayContainer{1, 100, 10000}, r.containers[0])
+	})
+}
+
+func TestRoaringAgainstModel(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := NewRoaring()
+	model := make(map[uint64]bool)
+
+	for i := 0; i < 50_000; i++ {
+		// cluster values in a few containers so that every container kind gets exercised
+		v := uint64(rnd.Intn(4))<<16 | uint64(rnd.Intn(1<<14))
+		if rnd.Intn(4) == 0 {
+			r.Clear64(v)
+			delete(model, v)
+		} else {
+			r.Set64(v)
+			model[v] = true
+		}
+		if i%10_000 == 0 {
+			r.RunOptimize()
+		}
+	}
+
+	assert.Equal(t, len(model), r.Count())
+	assert.Equal(t, modelValues(model), slices.Collect(r.SetBits()))
+	for v := uint64(0); v < 4<<16; v += 7 {
+		assert.Equal(t, model[v], r.IsSet64(v))
+	}
+}
+
+func TestRoaringSetOps(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	build := func(n, spread int) (*Roaring, map[uint64]bool) {
+		r := NewRoaring()
+		m := make(map[uint64]bool)
+		for i := 0; i < n; i++ {
+			v := uint64(rnd.Intn(spread))
+			r.Set64(v)
+			m[v] = true
+		}
+		return r, m
+	}
+
+	// dense and sparse operands over overlapping containers
+	a, am := build(20_000, 3<<16)
+	b, bm := build(2_000, 5<<16)
+	b.RunOptimize()
+
+	tests := []struct {
+		name  string
+		op    func(x, y *Roaring)
+		model func(x, y bool) bool
+	}{
+		{"and", (*Roaring).And, func(x, y bool) bool { return x && y }},
+		{"or", (*Roaring).Or, func(x, y bool) bool { return x || y }},
+		{"xor", (*Roaring).Xor, func(x, y bool) bool { return x != y }},
+		{"and not", (*Roaring).AndNot, func(x, y bool) bool { return x && !y }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make(map[uint64]bool)
+			for v := range am {
+				if tt.model(true, bm[v]) {
+					want[v] = true
+				}
+			}
+			for v := range bm {
+				if tt.model(am[v], true) {
+					want[v] = true
+				}
+			}
+
+			got := a.Clone()
+			tt.op(got, b)
+			assert.Equal(t, modelValues(want), slices.Collect(got.SetBits()))
+			assert.Equal(t, len(want), got.Count())
+			assert.Equal(t, modelValues(am), slices.Collect(a.SetBits()), "operand must not change")
+		})
+	}
+
+	t.Run("with itself", func(t *testing.T) {
+		c := a.Clone()
+		c.And(c)
+		assert.Equal(t, a.Count(), c.Count())
+		c.Xor(c)
+		assert.Equal(t, 0, c.Count())
+	})
+}
+
+func TestRoaringMarshalBinary(t *testing.T) {
+	r := NewRoaring()
+	for i := 0; i < 100; i++ {
+		r.Set(i * 3) // array
+	}
+	for i := 0; i < 10_000; i++ {
+		r.Set64(1<<16 + uint64(i*5)) // bitmap
+	}
+	for i := 0; i < 30_000; i++ {
+		r.Set64(2<<16 + uint64(i)) // run after optimizing
+	}
+	r.Set64(1 << 62)
+	r.RunOptimize()
+
+	data, err := r.MarshalBinary()
+	require.NoError(t, err)
+
+	got := newRoaringWith(7)
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, r.Count(), got.Count())
+	assert.Equal(t, slices.Collect(r.SetBits()), slices.Collect(got.SetBits()))
+
+	t.Run("empty", func(t *testing.T) {
+		data, err := NewRoaring().MarshalBinary()
+		require.NoError(t, err)
+		got := newRoaringWith(1)
+		require.NoError(t, got.UnmarshalBinary(data))
+		assert.Equal(t, 0, got.Count())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data []byte
+		}{
+			{"empty", nil},
+			{"bad version", []byte{9, 0}},
+			{"truncated", data[:len(data)/2]},
+			{"trailing", append(slices.Clone(data), 0)},
+			{"unknown kind", []byte{roaringEncodingVersion, 1, 0, 9}},
+			{"empty array", []byte{roaringEncodingVersion, 1, 0, containerArray, 0}},
+			{"unsorted array", []byte{roaringEncodingVersion, 1, 0, containerArray, 2, 5, 0, 1, 0}},
+			{"overlapping runs", []byte{roaringEncodingVersion, 1, 0, containerRun, 2, 0, 0, 5, 0, 3, 0, 1, 0}},
+			{"keys out of order", []byte{roaringEncodingVersion, 2, 1, containerArray, 1, 0, 0, 0, containerArray, 1, 0, 0}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r := newRoaringWith(42)
+				assert.ErrorIs(t, r.UnmarshalBinary(tt.data), ErrInvalidEncoding)
+				assert.True(t, r.IsSet(42), "failed unmarshal must not modify the bitmap")
+			})
+		}
+	})
+}
+
+func TestRoaringSparseFootprint(t *testing.T) {
+	// a million IDs spread over a 2^40 range would need 128GB as a dense bitmap
+	rnd := rand.New(rand.NewSource(3))
+	r := NewRoaring()
+	for i := 0; i < 1_000_000; i++ {
+		r.Set64(uint64(rnd.Int63n(1 << 40)))
+	}
+	data, err := r.MarshalBinary()
+	require.NoError(t, err)
+	assert.Less(t, len(data), 16<<20)
+}
+
+func TestRoaringConcurrency(t *testing.T) {
+	r := NewRoaring()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for i := offset; i < 100_000; i += 8 {
+				r.Set(i)
+				_ = r.IsSet(i)
+				if i%2 == 0 {
+					r.Clear(i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 50_000, r.Count())
+}
+
+func BenchmarkRoaringSet(b *testing.B) {
+	r := NewRoaring()
+	rnd := rand.New(rand.NewSource(4))
+	values := make([]uint64, 1<<16)
+	for i := range values {
+		values[i] = uint64(rnd.Int63n(1 << 32))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Set64(values[i&(len(values)-1)])
+	}
+}
+
+func BenchmarkRoaringIsSet(b *testing.B) {
+	r := NewRoaring()
+	rnd := rand.New(rand.NewSource(5))
+	values := make([]uint64, 1<<16)
+	for i := range values {
+		values[i] = uint64(rnd.Int63n(1 << 32))
+		r.Set64(values[i])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.IsSet64(values[i&(len(values)-1)])
+	}
+}
@@ -0,0 +1,105 @@
+package bloom
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+const (
+	blockShift = 9
+	blockBits  = 1 << blockShift // a 64-byte cache line
+	blockWords = blockBits / 64
+)
+
+// BlockedBloomFilter represents a thread-safe blocked Bloom filter: the first hash picks a 64-byte block
+// and all k probes of an element land in it, so a lookup touches a single cache line.
+// Blocks receive uneven numbers of elements, so the filter needs more bits than a BloomFilter
+// for the same false positive rate, about 5% more at p=0.01 and 15% more at p=0.0001.
+type BlockedBloomFilter struct {
+	words  []atomic.Uint64
+	blocks uint64
+	k      uint32
+}
+
+// NewBlocked create blocked bloom filter
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewBlocked(n uint32, p float64) *BlockedBloomFilter {
+	m, k := estimateParameters(n, p)
+	blocks := (uint64(m) + blockBits - 1) / blockBits
+	for blockedFalsePositiveRate(max(n, 1), blocks, k) > p {
+		blocks += blocks/20 + 1
+	}
+	return &BlockedBloomFilter{
+		words:  make([]atomic.Uint64, blocks*blockWords),
+		blocks: blocks,
+		k:      k,
+	}
+}
+
+// Add add element to bloom filter
+func (bf *BlockedBloomFilter) Add(data []byte) {
+	block, x := bf.locate(data)
+	for i := uint32(0); i < bf.k; i++ {
+		var bit uint64
+		bit, x = nextProbe(x)
+		bf.words[block+bit/64].Or(1 << (bit % 64))
+	}
+}
+
+// Contains check if the element may exist
+func (bf *BlockedBloomFilter) Contains(data []byte) bool {
+	block, x := bf.locate(data)
+	for i := uint32(0); i < bf.k; i++ {
+		var bit uint64
+		bit, x = nextProbe(x)
+		if bf.words[block+bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// M returns the number of bits of the filter
+func (bf *BlockedBloomFilter) M() uint64 {
+	return bf.blocks * blockBits
+}
+
+// K returns the number of hash functions of the filter
+func (bf *BlockedBloomFilter) K() uint32 {
+	return bf.k
+}
+
+// locate returns the first word of the block of the element and the seed of its in-block probes
+func (bf *BlockedBloomFilter) locate(data []byte) (uint64, uint64) {
+	h1, h2 := BytesHasher(data)
+	// multiply-shift maps the hash to [0, blocks) without a division
+	hi, _ := bits.Mul64(h1, bf.blocks)
+	return hi * blockWords, h2
+}
+
+// nextProbe returns an in-block bit from the high bits of x and advances x with a 64-bit LCG.
+// Double hashing within 512 bits would only use 9 bits of each base hash, making the probes
+// of unrelated elements identical too often.
+func nextProbe(x uint64) (uint64, uint64) {
+	x = x*6364136223846793005 + 1442695040888963407
+	return x >> (64 - blockShift), x
+}
+
+// blockedFalsePositiveRate returns the expected false positive rate of n elements in blocks blocks.
+// The number of elements in a block follows a Poisson distribution of mean n/blocks,
+// the rate is the average over block loads of the rate of a 512-bit Bloom filter.
+func blockedFalsePositiveRate(n uint32, blocks uint64, k uint32) float64 {
+	mean := float64(n) / float64(blocks)
+	rate := 0.0
+	pmf := math.Exp(-mean) // P(load = 0)
+	for load := 0; load <= int(mean+10*math.Sqrt(mean)+10); load++ {
+		if load > 0 {
+			pmf *= mean / float64(load)
+		}
+		fill := 1 - math.Pow(1-1.0/blockBits, float64(load)*float64(k))
+		rate += pmf * math.Pow(fill, float64(k))
+	}
+	return rate
+}
@@ -0,0 +1,145 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockedBloomFilter(t *testing.T) {
+	bf := NewBlocked(1000, 0.01)
+	assert.Equal(t, uint32(7), bf.K())
+	assert.Zero(t, bf.M()%blockBits)
+	assert.False(t, bf.Contains([]byte("hello")))
+
+	bf.Add([]byte("hello"))
+	bf.Add([]byte(""))
+	assert.True(t, bf.Contains([]byte("hello")))
+	assert.True(t, bf.Contains(nil))
+	assert.False(t, bf.Contains([]byte("world")))
+
+	small := NewBlocked(0, 0.01)
+	small.Add([]byte("x"))
+	assert.True(t, small.Contains([]byte("x")))
+}
+
+func TestBlockedBloomFilterProbesOneBlock(t *testing.T) {
+	bf := NewBlocked(10_000, 0.01)
+	bf.Add([]byte("token"))
+	block, _ := bf.locate([]byte("token"))
+	for i := range bf.words {
+		inBlock := uint64(i) >= block && uint64(i) < block+blockWords
+		if !inBlock {
+			assert.Zero(t, bf.words[i].Load(), "word %d outside the block", i)
+		}
+	}
+}
+
+func TestBlockedFalsePositiveRate(t *testing.T) {
+	const n = 20_000
+	for _, p := range []float64{0.1, 0.01, 0.001} {
+		t.Run(fmt.Sprint(p), func(t *testing.T) {
+			bf := NewBlocked(n, p)
+			for i := 0; i < n; i++ {
+				bf.Add([]byte(fmt.Sprintf("member-%d", i)))
+			}
+
+			total := int(100 / p)
+			falsePositives := 0
+			for i := 0; i < total; i++ {
+				if bf.Contains([]byte(fmt.Sprintf("other-%d", i))) {
+					falsePositives++
+				}
+			}
+			fpRate := float64(falsePositives) / float64(total)
+			assert.Less(t, fpRate, p*1.3, "measured false positive rate %f", fpRate)
+		})
+	}
+}
+
+func TestBlockedFalsePositiveModel(t *testing.T) {
+	m, k := estimateParameters(100_000, 0.01)
+	blocks := uint64(m) / blockBits
+	// a blocked filter of the same size does worse than the Bloom filter
+	assert.Greater(t, blockedFalsePositiveRate(100_000, blocks, k), 0.01)
+	assert.Less(t, blockedFalsePositiveRate(100_000, blocks*2, k), 0.001)
+}
+
+func TestBlockedBloomFilterConcurrency(t *testing.T) {
+	bf := NewBlocked(10_000, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10_000; i += 8 {
+				data := []byte(fmt.Sprintf("key-%d", i))
+				bf.Add(data)
+				assert.True(t, bf.Contains(data))
+			}
+		}(g)
+	}
+	wg.Wait()
+	for i := 0; i < 10_000; i++ {
+		assert.True(t, bf.Contains([]byte(fmt.Sprintf("key-%d", i))))
+	}
+}
+
+// the filters are sized well past the CPU caches so that lookups pay for memory accesses
+const benchmarkElements = 10_000_000
+
+func benchmarkTokens(count int) [][]byte {
+	tokens := make([][]byte, count)
+	for i := range tokens {
+		tokens[i] = []byte(fmt.Sprintf("session-token-%d", i))
+	}
+	return tokens
+}
+
+func BenchmarkBlockedBloomFilterContains(b *testing.B) {
+	bf := NewBlocked(benchmarkElements, 0.01)
+	tokens := benchmarkTokens(1 << 16)
+	for _, token := range tokens {
+		bf.Add(token)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Contains(tokens[i&(len(tokens)-1)])
+	}
+}
+
+func BenchmarkBloomFilterContains(b *testing.B) {
+	bf := New(benchmarkElements, 0.01)
+	tokens := benchmarkTokens(1 << 16)
+	for _, token := range tokens {
+		bf.Add(token)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Contains(tokens[i&(len(tokens)-1)])
+	}
+}
+
+func BenchmarkBlockedBloomFilterAdd(b *testing.B) {
+	bf := NewBlocked(benchmarkElements, 0.01)
+	tokens := benchmarkTokens(1 << 16)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Add(tokens[i&(len(tokens)-1)])
+	}
+}
+
+func BenchmarkBloomFilterAdd(b *testing.B) {
+	bf := New(benchmarkElements, 0.01)
+	tokens := benchmarkTokens(1 << 16)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Add(tokens[i&(len(tokens)-1)])
+	}
+}
@@ -1,77 +1,67 @@
 package bloom
 
 import (
-	"hash/fnv"
 	"math"
 
 	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
 )
 
-// BloomFilter represents a thread-safe Bloom filter
+// BloomFilter represents a thread-safe Bloom filter of []byte elements
 type BloomFilter struct {
-	bitmap   *bitmap.Bitmap
-	hashFunc []func([]byte) uint32
-	size     uint32
+	*Bloom[[]byte]
 }
 
 // New create bloom filter
 // n: expected element count
 // p: expected false positive rate (0 < p < 1)
 func New(n uint32, p float64) *BloomFilter {
-	m, k := estimateParameters(n, p)
-	if k > 8 {
-		k = 8
-	}
-	return &BloomFilter{
-		bitmap:   bitmap.NewBitmap(int(m)),
-		hashFunc: createHashFunctions(k),
-		size:     m,
-	}
+	return NewWithBitmap(n, p, newLocalBitmap)
 }
 
-// Add add element to bloom filter
-func (bf *BloomFilter) Add(data []byte) {
-	for _, fn := range bf.hashFunc {
-		h := fn(data) % bf.size
-		bf.bitmap.Set(int(h))
-	}
+// NewWithBitmap create bloom filter on the bitmap returned by newBitmap for the computed size,
+// for example a bitmap.RedisBitmap shared by every server of a zone.
+// On a bitmap.RedisBitmap, Contains reports true while Redis fails, a false positive rather than a false negative,
+// and the error is available from the Err method of the bitmap.
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewWithBitmap(n uint32, p float64, newBitmap func(size int) bitmap.Interface) *BloomFilter {
+	return &BloomFilter{newBloom(n, p, BytesHasher, kindBytes, newBitmap)}
 }
 
-// Contains check if the element may exist
-func (bf *BloomFilter) Contains(data []byte) bool {
-	for _, fn := range bf.hashFunc {
-		h := fn(data) % bf.size
-		if !bf.bitmap.IsSet(int(h)) {
-			return false
-		}
-	}
-	return true
+// Union adds the elements of other to the filter.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *BloomFilter) Union(other *BloomFilter) error {
+	return bf.Bloom.Union(other.Bloom)
 }
 
-// estimateParameters calculate optimal parameters (m: array size, k: hash function count)
-func estimateParameters(n uint32, p float64) (uint32, uint32) {
-	m := uint32(math.Ceil(-float64(n) * math.Log(p) / (math.Pow(math.Log(2), 2))))
-	k := uint32(math.Ceil(math.Log(2) * float64(m) / float64(n)))
-	return m, k
+// Intersect keeps in the filter only the elements that may also exist in other.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *BloomFilter) Intersect(other *BloomFilter) error {
+	return bf.Bloom.Intersect(other.Bloom)
 }
 
-// createHashFunctions create k hash functions (using double hash technique)
-func createHashFunctions(k uint32) []func([]byte) uint32 {
-	h1 := fnv.New32a()
-	h2 := fnv.New32()
-
-	base := []func([]byte) uint32{
-		func(data []byte) uint32 { h1.Reset(); h1.Write(data); return h1.Sum32() },
-		func(data []byte) uint32 { h2.Reset(); h2.Write(data); return h2.Sum32() },
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it can be called on a zero BloomFilter
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	if bf.Bloom == nil {
+		bf.Bloom = &Bloom[[]byte]{hash: BytesHasher, kind: kindBytes}
 	}
+	return bf.Bloom.UnmarshalBinary(data)
+}
+
+// newLocalBitmap create the process-local bitmap used by default
+func newLocalBitmap(size int) bitmap.Interface {
+	return bitmap.NewBitmap(size)
+}
+
+// maxHashFunctions is the largest k of a filter, the optimal k exceeds it only for p below about 5e-20
+const maxHashFunctions = 64
 
-	fns := make([]func([]byte) uint32, 0, k)
-	for i := uint32(0); i < k; i++ {
-		idx := i % uint32(len(base))
-		factor := i/uint32(len(base)) + 1
-		fns = append(fns, func(data []byte) uint32 {
-			return base[idx](data) * uint32(factor)
-		})
+// estimateParameters calculate optimal parameters (m: array size, k: hash function count)
+func estimateParameters(n uint32, p float64) (uint32, uint32) {
+	if n == 0 {
+		n = 1
 	}
-	return fns
+	m := uint32(math.Ceil(-float64(n) * math.Log(p) / (math.Pow(math.Log(2), 2))))
+	k := uint32(math.Ceil(math.Log(2) * float64(m) / float64(n)))
+	return m, min(max(k, 1), maxHashFunctions)
 }
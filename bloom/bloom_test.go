@@ -1,11 +1,16 @@
 package bloom
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
 )
 
 func TestBloomFilter(t *testing.T) {
@@ -49,6 +54,49 @@ func TestEdgeCases(t *testing.T) {
 	})
 }
 
+func TestFalsePositiveRate(t *testing.T) {
+	const n = 20_000
+	for _, p := range []float64{0.1, 0.01, 0.001} {
+		t.Run(fmt.Sprint(p), func(t *testing.T) {
+			bf := New(n, p)
+			for i := 0; i < n; i++ {
+				bf.Add([]byte(fmt.Sprintf("member-%d", i)))
+			}
+
+			total := int(100 / p)
+			falsePositives := 0
+			for i := 0; i < total; i++ {
+				if bf.Contains([]byte(fmt.Sprintf("other-%d", i))) {
+					falsePositives++
+				}
+			}
+			fpRate := float64(falsePositives) / float64(total)
+			assert.InDelta(t, p, fpRate, p*0.3, "measured false positive rate %f", fpRate)
+		})
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	bf := New(10_000, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10_000; i += 8 {
+				data := []byte(fmt.Sprintf("key-%d", i))
+				bf.Add(data)
+				assert.True(t, bf.Contains(data))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10_000; i++ {
+		assert.True(t, bf.Contains([]byte(fmt.Sprintf("key-%d", i))))
+	}
+}
+
 func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyz"
 	b := make([]byte, length)
@@ -71,3 +119,38 @@ func BenchmarkBloomFilter(b *testing.B) {
 		bf.Contains(data[i])
 	}
 }
+
+func TestRedisBackedBloomFilter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	newBitmap := func(size int) bitmap.Interface {
+		return bitmap.NewRedisBitmap(rdb, "bloom:shared", size, 0)
+	}
+
+	// two filters on the same key behave like one filter shared by two servers
+	server1 := NewWithBitmap(1000, 0.01, newBitmap)
+	server2 := NewWithBitmap(1000, 0.01, newBitmap)
+
+	server1.Add([]byte("hello"))
+	assert.True(t, server2.Contains([]byte("hello")))
+	assert.False(t, server2.Contains([]byte("world")))
+
+	ints1 := NewInt64BloomWithBitmap(1000, 0.01, func(size int) bitmap.Interface {
+		return bitmap.NewRedisBitmap(rdb, "bloom:int64", size, 0)
+	})
+	ints2 := NewInt64BloomWithBitmap(1000, 0.01, func(size int) bitmap.Interface {
+		return bitmap.NewRedisBitmap(rdb, "bloom:int64", size, 0)
+	})
+	ints1.Add(42)
+	assert.True(t, ints2.Contains(42))
+	assert.False(t, ints2.Contains(43))
+
+	// a failing Redis gives false positives, never false negatives
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+	assert.True(t, server2.Contains([]byte("world")))
+	assert.True(t, ints2.Contains(43))
+	mr.SetError("")
+	assert.False(t, server2.Contains([]byte("world")))
+}
@@ -0,0 +1,136 @@
+package bloom
+
+import (
+	"sync/atomic"
+)
+
+const (
+	counterBits     = 4
+	counterMax      = 1<<counterBits - 1
+	countersPerWord = 64 / counterBits
+)
+
+// CountingBloomFilter represents a thread-safe Bloom filter supporting removal.
+// Every bit of a BloomFilter is replaced by a 4-bit counter, 16 counters are packed in a word.
+// Counters saturate at 15 and then never decrease, so elements added very often cannot be fully removed
+// but removing never causes false negatives for other elements.
+type CountingBloomFilter struct {
+	counters []atomic.Uint64
+	k        uint32
+	size     uint32
+}
+
+// NewCounting create counting bloom filter, sized like New
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewCounting(n uint32, p float64) *CountingBloomFilter {
+	m, k := estimateParameters(n, p)
+	return &CountingBloomFilter{
+		counters: make([]atomic.Uint64, (m+countersPerWord-1)/countersPerWord),
+		k:        k,
+		size:     m,
+	}
+}
+
+// Add add element to bloom filter
+func (bf *CountingBloomFilter) Add(data []byte) {
+	bf.add(BytesHasher(data))
+}
+
+// AddInt64 add int64 element to bloom filter
+func (bf *CountingBloomFilter) AddInt64(data int64) {
+	bf.add(Int64Hasher(data))
+}
+
+// Remove remove element from bloom filter, returns false if the element does not exist
+func (bf *CountingBloomFilter) Remove(data []byte) bool {
+	return bf.remove(BytesHasher(data))
+}
+
+// RemoveInt64 remove int64 element from bloom filter, returns false if the element does not exist
+func (bf *CountingBloomFilter) RemoveInt64(data int64) bool {
+	return bf.remove(Int64Hasher(data))
+}
+
+// Contains check if the element may exist
+func (bf *CountingBloomFilter) Contains(data []byte) bool {
+	return bf.count(BytesHasher(data)) > 0
+}
+
+// ContainsInt64 check if the int64 element may exist
+func (bf *CountingBloomFilter) ContainsInt64(data int64) bool {
+	return bf.count(Int64Hasher(data)) > 0
+}
+
+// Count estimate how many times the element was added and not removed.
+// Like Contains it may overestimate but never underestimates, and it is capped at 15.
+func (bf *CountingBloomFilter) Count(data []byte) int {
+	return bf.count(BytesHasher(data))
+}
+
+// CountInt64 estimate how many times the int64 element was added and not removed, capped at 15
+func (bf *CountingBloomFilter) CountInt64(data int64) int {
+	return bf.count(Int64Hasher(data))
+}
+
+func (bf *CountingBloomFilter) add(h1, h2 uint64) {
+	for _, index := range bf.indexes(h1, h2) {
+		bf.update(index, true)
+	}
+}
+
+func (bf *CountingBloomFilter) remove(h1, h2 uint64) bool {
+	indexes := bf.indexes(h1, h2)
+	if bf.min(indexes) == 0 {
+		return false
+	}
+	for _, index := range indexes {
+		bf.update(index, false)
+	}
+	return true
+}
+
+func (bf *CountingBloomFilter) count(h1, h2 uint64) int {
+	return bf.min(bf.indexes(h1, h2))
+}
+
+// indexes returns the counter indexes of the element
+func (bf *CountingBloomFilter) indexes(h1, h2 uint64) []int {
+	return appendIndexes(make([]int, 0, bf.k), h1, h2, bf.k, bf.size)
+}
+
+// min returns the smallest of the counters at indexes
+func (bf *CountingBloomFilter) min(indexes []int) int {
+	result := counterMax
+	for _, index := range indexes {
+		result = min(result, bf.counter(index))
+	}
+	return result
+}
+
+// counter returns the value of the counter at index
+func (bf *CountingBloomFilter) counter(index int) int {
+	shift := uint(index%countersPerWord) * counterBits
+	return int(bf.counters[index/countersPerWord].Load() >> shift & counterMax)
+}
+
+// update increments or decrements the counter at index, leaving saturated counters unchanged
+func (bf *CountingBloomFilter) update(index int, increment bool) {
+	word := &bf.counters[index/countersPerWord]
+	shift := uint(index%countersPerWord) * counterBits
+	for {
+		old := word.Load()
+		c := old >> shift & counterMax
+		if c == counterMax || (!increment && c == 0) {
+			return
+		}
+
+		next := old + 1<<shift
+		if !increment {
+			next = old - 1<<shift
+		}
+		if word.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingBloomFilter(t *testing.T) {
+	bf := NewCounting(1000, 0.01)
+	assert.False(t, bf.Contains([]byte("hello")))
+	assert.False(t, bf.Remove([]byte("hello")), "removing a missing element does nothing")
+
+	bf.Add([]byte("hello"))
+	bf.Add([]byte("world"))
+	bf.Add([]byte("hello"))
+	assert.True(t, bf.Contains([]byte("hello")))
+	assert.True(t, bf.Contains([]byte("world")))
+	assert.Equal(t, 2, bf.Count([]byte("hello")))
+	assert.Equal(t, 1, bf.Count([]byte("world")))
+
+	assert.True(t, bf.Remove([]byte("hello")))
+	assert.True(t, bf.Contains([]byte("hello")))
+	assert.True(t, bf.Remove([]byte("hello")))
+	assert.False(t, bf.Contains([]byte("hello")))
+	assert.True(t, bf.Contains([]byte("world")), "removing must not affect other elements")
+	assert.Equal(t, 0, bf.Count([]byte("hello")))
+}
+
+func TestCountingBloomFilterInt64(t *testing.T) {
+	bf := NewCounting(1000, 0.01)
+	for _, d := range []int64{0, -1, 1<<63 - 1} {
+		bf.AddInt64(d)
+		assert.True(t, bf.ContainsInt64(d))
+		assert.Equal(t, 1, bf.CountInt64(d))
+	}
+	assert.True(t, bf.RemoveInt64(-1))
+	assert.False(t, bf.ContainsInt64(-1))
+	assert.False(t, bf.RemoveInt64(-1))
+	assert.True(t, bf.ContainsInt64(0))
+}
+
+func TestCountingBloomFilterSaturation(t *testing.T) {
+	bf := NewCounting(100, 0.01)
+	for i := 0; i < 20; i++ {
+		bf.Add([]byte("hot"))
+	}
+	assert.Equal(t, counterMax, bf.Count([]byte("hot")))
+
+	// saturated counters no longer decrease, the element stays present
+	for i := 0; i < 20; i++ {
+		bf.Remove([]byte("hot"))
+	}
+	assert.True(t, bf.Contains([]byte("hot")))
+}
+
+func TestCountingBloomFilterRemoveKeepsOthers(t *testing.T) {
+	const n = 5000
+	bf := NewCounting(n, 0.01)
+	for i := 0; i < n; i++ {
+		bf.Add([]byte(fmt.Sprintf("mail-%d", i)))
+	}
+	for i := 0; i < n; i += 2 {
+		assert.True(t, bf.Remove([]byte(fmt.Sprintf("mail-%d", i))))
+	}
+
+	present := 0
+	for i := 0; i < n; i++ {
+		if bf.Contains([]byte(fmt.Sprintf("mail-%d", i))) {
+			present++
+		}
+		if i%2 == 1 {
+			assert.True(t, bf.Contains([]byte(fmt.Sprintf("mail-%d", i))), "no false negatives")
+		}
+	}
+	assert.Less(t, present, n/2+n/20, "removed elements are mostly gone")
+}
+
+func TestCountingBloomFilterConcurrency(t *testing.T) {
+	bf := NewCounting(10_000, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10_000; i += 8 {
+				bf.AddInt64(int64(i))
+				bf.AddInt64(int64(i))
+				bf.RemoveInt64(int64(i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10_000; i++ {
+		assert.True(t, bf.ContainsInt64(int64(i)))
+	}
+}
+
+func BenchmarkCountingBloomFilter(b *testing.B) {
+	bf := NewCounting(1000000, 0.01)
+	data := make([][]byte, b.N)
+	for i := range data {
+		data[i] = []byte(randomString(10))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Add(data[i])
+		bf.Contains(data[i])
+	}
+}
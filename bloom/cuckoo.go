@@ -0,0 +1,332 @@
+package bloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultFingerprintBits = 16
+	DefaultBucketSize      = 4
+	DefaultMaxKicks        = 500
+
+	cuckooMaxLoad = 0.95 // load factor the table is sized for
+)
+
+var ErrFilterFull = errors.New("cuckoo filter is full")
+
+// CuckooOptions configures a CuckooFilter
+type CuckooOptions struct {
+	// FingerprintBits is the size of the fingerprint stored per element, from 1 to 32,
+	// DefaultFingerprintBits if zero. The false positive rate is about 2*BucketSize/2^FingerprintBits.
+	FingerprintBits int
+	// BucketSize is the number of fingerprints per bucket, from 1 to 16, DefaultBucketSize if zero
+	BucketSize int
+	// MaxKicks is the number of relocations tried before Insert fails, DefaultMaxKicks if zero
+	MaxKicks int
+	// Rand chooses the relocations of Insert, the global source if nil.
+	// It is only used while the filter is locked, a seeded source makes insertions reproducible.
+	Rand *rand.Rand
+}
+
+// CuckooFilter represents a thread-safe cuckoo filter, an approximate set supporting deletion.
+// Every element is stored as a fingerprint in one of two candidate buckets, fingerprints are bit-packed.
+type CuckooFilter struct {
+	mutex sync.RWMutex
+	words []uint64
+	count int
+
+	fpBits     uint
+	bucketSize int
+	buckets    uint64 // a power of two
+	maxKicks   int
+	rand       *rand.Rand
+}
+
+// NewCuckoo create cuckoo filter
+// n: expected element count
+func NewCuckoo(n uint32, opts CuckooOptions) *CuckooFilter {
+	cf := &CuckooFilter{
+		fpBits:     DefaultFingerprintBits,
+		bucketSize: DefaultBucketSize,
+		maxKicks:   DefaultMaxKicks,
+		rand:       opts.Rand,
+	}
+	if opts.FingerprintBits != 0 {
+		cf.fpBits = uint(opts.FingerprintBits)
+	}
+	if opts.BucketSize != 0 {
+		cf.bucketSize = opts.BucketSize
+	}
+	if opts.MaxKicks != 0 {
+		cf.maxKicks = opts.MaxKicks
+	}
+	if cf.fpBits < 1 || cf.fpBits > 32 || cf.bucketSize < 1 || cf.bucketSize > 16 || cf.maxKicks < 0 {
+		panic("cuckoo filter options out of range")
+	}
+
+	buckets := uint64(float64(max(n, 1)) / cuckooMaxLoad / float64(cf.bucketSize))
+	cf.buckets = 1 << bits.Len64(max(buckets, 1)-1)
+	cf.words = make([]uint64, cf.wordCount())
+	return cf
+}
+
+// Insert add element to the filter, returns ErrFilterFull if no room is found after MaxKicks relocations,
+// in which case the filter is left unchanged.
+// An element can be inserted several times and must then be deleted as many times.
+func (cf *CuckooFilter) Insert(data []byte) error {
+	i1, fp := cf.locate(data)
+
+	cf.mutex.Lock()
+	defer cf.mutex.Unlock()
+
+	i2 := cf.altIndex(i1, fp)
+	if cf.insertInto(i1, fp) || cf.insertInto(i2, fp) {
+		cf.count++
+		return nil
+	}
+
+	// relocate fingerprints along a random walk, remembering it to undo a failed insertion
+	type kick struct {
+		slot uint64
+		fp   uint32
+	}
+	path := make([]kick, 0, cf.maxKicks)
+	i := i1
+	if cf.randIntN(2) == 0 {
+		i = i2
+	}
+	for n := 0; n < cf.maxKicks; n++ {
+		slot := i*uint64(cf.bucketSize) + uint64(cf.randIntN(cf.bucketSize))
+		old := cf.get(slot)
+		cf.set(slot, fp)
+		path = append(path, kick{slot: slot, fp: old})
+
+		fp = old
+		i = cf.altIndex(i, fp)
+		if cf.insertInto(i, fp) {
+			cf.count++
+			return nil
+		}
+	}
+	for n := len(path) - 1; n >= 0; n-- {
+		cf.set(path[n].slot, path[n].fp)
+	}
+	return ErrFilterFull
+}
+
+// randIntN returns a random number in [0, n) from the source of the filter
+func (cf *CuckooFilter) randIntN(n int) int {
+	if cf.rand == nil {
+		return rand.IntN(n)
+	}
+	return cf.rand.IntN(n)
+}
+
+// Lookup check if the element may exist
+func (cf *CuckooFilter) Lookup(data []byte) bool {
+	i1, fp := cf.locate(data)
+
+	cf.mutex.RLock()
+	defer cf.mutex.RUnlock()
+	return cf.find(i1, fp) >= 0 || cf.find(cf.altIndex(i1, fp), fp) >= 0
+}
+
+// Delete remove one copy of the element, returns false if it does not exist.
+// Deleting an element that was never inserted may remove another element sharing its fingerprint.
+func (cf *CuckooFilter) Delete(data []byte) bool {
+	i1, fp := cf.locate(data)
+
+	cf.mutex.Lock()
+	defer cf.mutex.Unlock()
+	slot := cf.find(i1, fp)
+	if slot < 0 {
+		slot = cf.find(cf.altIndex(i1, fp), fp)
+	}
+	if slot < 0 {
+		return false
+	}
+	cf.set(uint64(slot), 0)
+	cf.count--
+	return true
+}
+
+// Count returns the number of elements stored
+func (cf *CuckooFilter) Count() int {
+	cf.mutex.RLock()
+	defer cf.mutex.RUnlock()
+	return cf.count
+}
+
+// Capacity returns the number of fingerprint slots
+func (cf *CuckooFilter) Capacity() int {
+	return int(cf.buckets) * cf.bucketSize
+}
+
+// locate returns the first bucket and the fingerprint of the element, fingerprints are never 0
+func (cf *CuckooFilter) locate(data []byte) (uint64, uint32) {
+	h1, h2 := BytesHasher(data)
+	fp := uint32(h2 & (1<<cf.fpBits - 1))
+	if fp == 0 {
+		fp = 1
+	}
+	return h1 & (cf.buckets - 1), fp
+}
+
+// altIndex returns the other bucket of fingerprint fp stored in bucket i,
+// altIndex(altIndex(i, fp), fp) == i as the bucket count is a power of two
+func (cf *CuckooFilter) altIndex(i uint64, fp uint32) uint64 {
+	return (i ^ (uint64(fp) * 0x9e3779b97f4a7c15 >> 32)) & (cf.buckets - 1)
+}
+
+// insertInto stores fp in a free slot of bucket i
+func (cf *CuckooFilter) insertInto(i uint64, fp uint32) bool {
+	slot := cf.find(i, 0)
+	if slot < 0 {
+		return false
+	}
+	cf.set(uint64(slot), fp)
+	return true
+}
+
+// find returns the first slot of bucket i holding fp, or -1
+func (cf *CuckooFilter) find(i uint64, fp uint32) int64 {
+	start := i * uint64(cf.bucketSize)
+	for slot := start; slot < start+uint64(cf.bucketSize); slot++ {
+		if cf.get(slot) == fp {
+			return int64(slot)
+		}
+	}
+	return -1
+}
+
+// get returns the fingerprint in slot, 0 for an empty slot
+func (cf *CuckooFilter) get(slot uint64) uint32 {
+	offset := slot * uint64(cf.fpBits)
+	w, shift := offset>>6, offset&63
+	v := cf.words[w] >> shift
+	if shift+uint64(cf.fpBits) > 64 {
+		v |= cf.words[w+1] << (64 - shift)
+	}
+	return uint32(v & (1<<cf.fpBits - 1))
+}
+
+// set stores fp in slot
+func (cf *CuckooFilter) set(slot uint64, fp uint32) {
+	offset := slot * uint64(cf.fpBits)
+	w, shift := offset>>6, offset&63
+	mask := uint64(1)<<cf.fpBits - 1
+	cf.words[w] = cf.words[w]&^(mask<<shift) | uint64(fp)<<shift
+	if shift+uint64(cf.fpBits) > 64 {
+		rest := 64 - shift
+		cf.words[w+1] = cf.words[w+1]&^(mask>>rest) | uint64(fp)>>rest
+	}
+}
+
+func (cf *CuckooFilter) wordCount() uint64 {
+	return (cf.buckets*uint64(cf.bucketSize)*uint64(cf.fpBits) + 63) / 64
+}
+
+// Binary format of a marshaled CuckooFilter:
+//
+//	version      1 byte
+//	kind         1 byte
+//	hash         1 byte, the hashing scheme
+//	fingerprint  uvarint, the bits per fingerprint
+//	bucket size  uvarint
+//	buckets      uvarint
+//	count        uvarint
+//	words        the packed fingerprints as little-endian uint64
+const kindCuckoo byte = 3
+
+var (
+	_ encoding.BinaryMarshaler   = (*CuckooFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*CuckooFilter)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (cf *CuckooFilter) MarshalBinary() ([]byte, error) {
+	cf.mutex.RLock()
+	defer cf.mutex.RUnlock()
+
+	data := make([]byte, 0, 3+4*binary.MaxVarintLen64+8*len(cf.words))
+	data = append(data, encodingVersion, kindCuckoo, hashMurmur3DoubleHashing)
+	data = binary.AppendUvarint(data, uint64(cf.fpBits))
+	data = binary.AppendUvarint(data, uint64(cf.bucketSize))
+	data = binary.AppendUvarint(data, cf.buckets)
+	data = binary.AppendUvarint(data, uint64(cf.count))
+	for _, w := range cf.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the filter by the decoded one.
+// It can be called on a zero CuckooFilter, which then uses DefaultMaxKicks.
+func (cf *CuckooFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return errors.Wrap(ErrInvalidEncoding, "header too short")
+	}
+	if data[0] != encodingVersion {
+		return errors.Wrapf(ErrInvalidEncoding, "unsupported version %d", data[0])
+	}
+	if data[1] != kindCuckoo {
+		return errors.Wrapf(ErrIncompatible, "element kind %d, expected %d", data[1], kindCuckoo)
+	}
+	if data[2] != hashMurmur3DoubleHashing {
+		return errors.Wrapf(ErrIncompatible, "unknown hashing scheme %d", data[2])
+	}
+	data = data[3:]
+
+	var fields [4]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.Wrap(ErrInvalidEncoding, "truncated header")
+		}
+		fields[i], data = v, data[n:]
+	}
+	fpBits, bucketSize, buckets, count := fields[0], fields[1], fields[2], fields[3]
+	if fpBits < 1 || fpBits > 32 || bucketSize < 1 || bucketSize > 16 ||
+		buckets == 0 || buckets&(buckets-1) != 0 || buckets > 1<<40 || count > buckets*bucketSize {
+		return errors.Wrap(ErrInvalidEncoding, "invalid parameters")
+	}
+
+	decoded := &CuckooFilter{
+		fpBits:     uint(fpBits),
+		bucketSize: int(bucketSize),
+		buckets:    buckets,
+		count:      int(count),
+	}
+	if uint64(len(data)) != 8*decoded.wordCount() {
+		return errors.Wrapf(ErrInvalidEncoding, "payload length %d does not match %d buckets", len(data), buckets)
+	}
+	decoded.words = make([]uint64, decoded.wordCount())
+	for i := range decoded.words {
+		decoded.words[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+
+	stored := 0
+	for slot := uint64(0); slot < buckets*bucketSize; slot++ {
+		if decoded.get(slot) != 0 {
+			stored++
+		}
+	}
+	if stored != decoded.count {
+		return errors.Wrapf(ErrInvalidEncoding, "%d fingerprints stored for count %d", stored, count)
+	}
+
+	cf.mutex.Lock()
+	defer cf.mutex.Unlock()
+	cf.words, cf.count = decoded.words, decoded.count
+	cf.fpBits, cf.bucketSize, cf.buckets = decoded.fpBits, decoded.bucketSize, decoded.buckets
+	if cf.maxKicks == 0 {
+		cf.maxKicks = DefaultMaxKicks
+	}
+	return nil
+}
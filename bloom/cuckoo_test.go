@@ -0,0 +1,182 @@
+package bloom
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	cf := NewCuckoo(1000, CuckooOptions{})
+	assert.False(t, cf.Lookup([]byte("hello")))
+	assert.False(t, cf.Delete([]byte("hello")))
+
+	require.NoError(t, cf.Insert([]byte("hello")))
+	require.NoError(t, cf.Insert([]byte("world")))
+	require.NoError(t, cf.Insert([]byte("hello")))
+	assert.True(t, cf.Lookup([]byte("hello")))
+	assert.True(t, cf.Lookup([]byte("world")))
+	assert.Equal(t, 3, cf.Count())
+
+	assert.True(t, cf.Delete([]byte("hello")))
+	assert.True(t, cf.Lookup([]byte("hello")), "a second copy remains")
+	assert.True(t, cf.Delete([]byte("hello")))
+	assert.False(t, cf.Lookup([]byte("hello")))
+	assert.True(t, cf.Lookup([]byte("world")))
+	assert.Equal(t, 1, cf.Count())
+
+	assert.Panics(t, func() { NewCuckoo(10, CuckooOptions{FingerprintBits: 33}) })
+	assert.Panics(t, func() { NewCuckoo(10, CuckooOptions{BucketSize: -1}) })
+}
+
+func TestCuckooFilterFingerprintPacking(t *testing.T) {
+	for _, fpBits := range []int{1, 7, 13, 16, 31, 32} {
+		t.Run(fmt.Sprint(fpBits), func(t *testing.T) {
+			cf := NewCuckoo(100, CuckooOptions{FingerprintBits: fpBits, BucketSize: 3})
+			slots := uint64(cf.Capacity())
+			want := make([]uint32, slots)
+			for slot := range want {
+				want[slot] = uint32(slot*2654435761) & (1<<fpBits - 1)
+				cf.set(uint64(slot), want[slot])
+			}
+			for slot := uint64(0); slot < slots; slot++ {
+				assert.Equal(t, want[slot], cf.get(slot), "slot %d", slot)
+			}
+		})
+	}
+}
+
+func TestCuckooFilterFull(t *testing.T) {
+	// a seeded source makes the load reached reproducible
+	cf := NewCuckoo(1000, CuckooOptions{MaxKicks: 50, Rand: rand.New(rand.NewPCG(1, 2))})
+	var inserted [][]byte
+	var err error
+	for i := 0; i < 10*cf.Capacity(); i++ {
+		data := []byte(fmt.Sprintf("key-%d", i))
+		if err = cf.Insert(data); err != nil {
+			break
+		}
+		inserted = append(inserted, data)
+	}
+	require.ErrorIs(t, err, ErrFilterFull)
+	assert.Equal(t, len(inserted), cf.Count())
+	assert.Greater(t, float64(cf.Count())/float64(cf.Capacity()), 0.9, "a bucket size of 4 allows high load")
+
+	// a failed insertion leaves the filter unchanged
+	for _, data := range inserted {
+		assert.True(t, cf.Lookup(data))
+	}
+	assert.True(t, cf.Delete(inserted[0]))
+	assert.NoError(t, cf.Insert(inserted[0]))
+
+	// the same source gives the same filter
+	again := NewCuckoo(1000, CuckooOptions{MaxKicks: 50, Rand: rand.New(rand.NewPCG(1, 2))})
+	for i := 0; i < len(inserted); i++ {
+		require.NoError(t, again.Insert(inserted[i]))
+	}
+	assert.ErrorIs(t, again.Insert([]byte(fmt.Sprintf("key-%d", len(inserted)))), ErrFilterFull)
+}
+
+func TestCuckooFalsePositiveRate(t *testing.T) {
+	tests := []struct {
+		fpBits, bucketSize int
+	}{
+		{8, 4},
+		{12, 4},
+		{12, 2},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d bits %d slots", tt.fpBits, tt.bucketSize), func(t *testing.T) {
+			const n = 10_000
+			cf := NewCuckoo(n, CuckooOptions{FingerprintBits: tt.fpBits, BucketSize: tt.bucketSize})
+			for i := 0; i < n; i++ {
+				require.NoError(t, cf.Insert([]byte(fmt.Sprintf("member-%d", i))))
+			}
+
+			total, falsePositives := 100_000, 0
+			for i := 0; i < total; i++ {
+				if cf.Lookup([]byte(fmt.Sprintf("other-%d", i))) {
+					falsePositives++
+				}
+			}
+			fpRate := float64(falsePositives) / float64(total)
+			bound := 2 * float64(tt.bucketSize) / float64(uint(1)<<tt.fpBits)
+			assert.Less(t, fpRate, bound, "measured false positive rate %f", fpRate)
+		})
+	}
+}
+
+func TestCuckooFilterMarshalBinary(t *testing.T) {
+	cf := NewCuckoo(1000, CuckooOptions{FingerprintBits: 12, BucketSize: 2})
+	for i := 0; i < 500; i++ {
+		require.NoError(t, cf.Insert([]byte(fmt.Sprintf("key-%d", i))))
+	}
+
+	data, err := cf.MarshalBinary()
+	require.NoError(t, err)
+
+	var got CuckooFilter
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, 500, got.Count())
+	assert.Equal(t, cf.Capacity(), got.Capacity())
+	for i := 0; i < 500; i++ {
+		assert.True(t, got.Lookup([]byte(fmt.Sprintf("key-%d", i))))
+	}
+	assert.True(t, got.Delete([]byte("key-0")))
+	assert.NoError(t, got.Insert([]byte("new")))
+
+	t.Run("invalid", func(t *testing.T) {
+		bloomData, err := New(100, 0.01).MarshalBinary()
+		require.NoError(t, err)
+		cf.count++
+		wrongCount, err := cf.MarshalBinary()
+		require.NoError(t, err)
+		cf.count--
+
+		var empty CuckooFilter
+		assert.ErrorIs(t, empty.UnmarshalBinary(bloomData), ErrIncompatible)
+		assert.ErrorIs(t, empty.UnmarshalBinary(nil), ErrInvalidEncoding)
+		assert.ErrorIs(t, empty.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+		assert.ErrorIs(t, empty.UnmarshalBinary(wrongCount), ErrInvalidEncoding)
+		assert.ErrorIs(t, empty.UnmarshalBinary([]byte{encodingVersion, kindCuckoo, hashMurmur3DoubleHashing, 8, 4, 3, 0}), ErrInvalidEncoding)
+	})
+}
+
+func TestCuckooFilterConcurrency(t *testing.T) {
+	cf := NewCuckoo(10_000, CuckooOptions{})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 8_000; i += 8 {
+				data := []byte(fmt.Sprintf("key-%d", i))
+				assert.NoError(t, cf.Insert(data))
+				assert.True(t, cf.Lookup(data))
+				if i%2 == 0 {
+					assert.True(t, cf.Delete(data))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 4_000, cf.Count())
+}
+
+func BenchmarkCuckooFilter(b *testing.B) {
+	cf := NewCuckoo(1000000, CuckooOptions{})
+	data := make([][]byte, b.N)
+	for i := range data {
+		data[i] = []byte(randomString(10))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cf.Insert(data[i])
+		cf.Lookup(data[i])
+	}
+}
@@ -0,0 +1,145 @@
+package bloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
+)
+
+var (
+	ErrInvalidEncoding = errors.New("invalid bloom filter encoding")
+	ErrIncompatible    = errors.New("incompatible bloom filters")
+)
+
+// Binary format of a marshaled filter:
+//
+//	version  1 byte
+//	kind     1 byte, the element type of the filter
+//	hash     1 byte, the hashing scheme
+//	m        uvarint, the number of bits
+//	k        uvarint, the number of hash functions, at most maxHashFunctions and m
+//	bits     the marshaled bitmap.Bitmap
+const encodingVersion = 1
+
+const (
+	kindCustom byte = 0 // a Bloom with a caller provided Hasher
+	kindBytes  byte = 1
+	kindInt64  byte = 2
+)
+
+const hashMurmur3DoubleHashing byte = 1 // Kirsch–Mitzenmacher probes over a 128-bit hash
+
+var (
+	_ encoding.BinaryMarshaler   = (*Bloom[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*Bloom[string])(nil)
+	_ encoding.BinaryMarshaler   = (*BloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*BloomFilter)(nil)
+	_ encoding.BinaryMarshaler   = (*Int64BloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*Int64BloomFilter)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Only filters on the default process-local bitmap can be marshaled.
+func (bf *Bloom[T]) MarshalBinary() ([]byte, error) {
+	return marshalFilter(bf.kind, bf.size, bf.k, bf.bitmap)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the filter by the decoded one
+// on a process-local bitmap. The filter must hash keys like the marshaled one: a Bloom created by NewBloom
+// only loads filters marshaled by a Bloom with the same Hasher, which cannot be checked.
+// It must not be called concurrently with other methods of the filter.
+func (bf *Bloom[T]) UnmarshalBinary(data []byte) error {
+	if bf.hash == nil {
+		return errors.Wrap(ErrIncompatible, "bloom filter has no hasher")
+	}
+	m, k, bits, err := unmarshalFilter(bf.kind, data)
+	if err != nil {
+		return err
+	}
+	bf.bitmap, bf.size, bf.k = bits, m, k
+	return nil
+}
+
+// Union adds the elements of other to the filter.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *Bloom[T]) Union(other *Bloom[T]) error {
+	return mergeFilter(bf.bitmap, other.bitmap, bf.size, bf.k, other.size, other.k, (*bitmap.Bitmap).Or)
+}
+
+// Intersect keeps in the filter only the elements that may also exist in other.
+// The false positive rate of the result is at most that of the filters, and can be higher than that
+// of a filter built from the common elements only.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *Bloom[T]) Intersect(other *Bloom[T]) error {
+	return mergeFilter(bf.bitmap, other.bitmap, bf.size, bf.k, other.size, other.k, (*bitmap.Bitmap).And)
+}
+
+func marshalFilter(kind byte, m, k uint32, bm bitmap.Interface) ([]byte, error) {
+	local, ok := bm.(*bitmap.Bitmap)
+	if !ok {
+		return nil, errors.Errorf("bloom filter on %T cannot be marshaled", bm)
+	}
+	bits, err := local.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, 3+2*binary.MaxVarintLen32+len(bits))
+	data = append(data, encodingVersion, kind, hashMurmur3DoubleHashing)
+	data = binary.AppendUvarint(data, uint64(m))
+	data = binary.AppendUvarint(data, uint64(k))
+	return append(data, bits...), nil
+}
+
+func unmarshalFilter(kind byte, data []byte) (uint32, uint32, *bitmap.Bitmap, error) {
+	if len(data) < 3 {
+		return 0, 0, nil, errors.Wrap(ErrInvalidEncoding, "header too short")
+	}
+	if data[0] != encodingVersion {
+		return 0, 0, nil, errors.Wrapf(ErrInvalidEncoding, "unsupported version %d", data[0])
+	}
+	if data[1] != kind {
+		return 0, 0, nil, errors.Wrapf(ErrIncompatible, "element kind %d, expected %d", data[1], kind)
+	}
+	if data[2] != hashMurmur3DoubleHashing {
+		return 0, 0, nil, errors.Wrapf(ErrIncompatible, "unknown hashing scheme %d", data[2])
+	}
+	data = data[3:]
+
+	m, n := binary.Uvarint(data)
+	if n <= 0 || m == 0 || m > 1<<32-1 {
+		return 0, 0, nil, errors.Wrap(ErrInvalidEncoding, "invalid bit count")
+	}
+	data = data[n:]
+	k, n := binary.Uvarint(data)
+	if n <= 0 || k == 0 || k > maxHashFunctions || k > m {
+		return 0, 0, nil, errors.Wrap(ErrInvalidEncoding, "invalid hash function count")
+	}
+	data = data[n:]
+
+	bits := bitmap.NewBitmap(0)
+	if err := bits.UnmarshalBinary(data); err != nil {
+		return 0, 0, nil, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	if uint64(bits.Size()) != m {
+		return 0, 0, nil, errors.Wrapf(ErrInvalidEncoding, "bitmap of %d bits for m=%d", bits.Size(), m)
+	}
+	return uint32(m), uint32(k), bits, nil
+}
+
+// mergeFilter applies op to the bitmap of a filter with the bitmap of another filter
+func mergeFilter(a, b bitmap.Interface, am, ak, bm, bk uint32, op func(x, y *bitmap.Bitmap)) error {
+	if am != bm || ak != bk {
+		return errors.Wrapf(ErrIncompatible, "m=%d k=%d and m=%d k=%d", am, ak, bm, bk)
+	}
+	al, aok := a.(*bitmap.Bitmap)
+	bl, bok := b.(*bitmap.Bitmap)
+	if !aok || !bok {
+		return errors.Wrapf(ErrIncompatible, "cannot merge filters on %T and %T", a, b)
+	}
+	op(al, bl)
+	return nil
+}
@@ -0,0 +1,171 @@
+package bloom
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
+)
+
+func TestBloomFilterMarshalBinary(t *testing.T) {
+	bf := New(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		bf.Add([]byte(fmt.Sprintf("device-%d", i)))
+	}
+
+	data, err := bf.MarshalBinary()
+	require.NoError(t, err)
+
+	var got BloomFilter
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, bf.size, got.size)
+	assert.Equal(t, bf.k, got.k)
+	for i := 0; i < 500; i++ {
+		assert.True(t, got.Contains([]byte(fmt.Sprintf("device-%d", i))))
+	}
+	assert.Equal(t, bf.bitmap.Count(), got.bitmap.Count())
+
+	// the loaded filter keeps working
+	got.Add([]byte("new"))
+	assert.True(t, got.Contains([]byte("new")))
+}
+
+func TestInt64BloomFilterMarshalBinary(t *testing.T) {
+	bf := NewInt64Bloom(1000, 0.01)
+	for i := int64(0); i < 500; i++ {
+		bf.Add(i * 7)
+	}
+
+	data, err := bf.MarshalBinary()
+	require.NoError(t, err)
+
+	got := NewInt64Bloom(10, 0.5)
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, bf.size, got.size)
+	assert.Equal(t, bf.k, got.k)
+	for i := int64(0); i < 500; i++ {
+		assert.True(t, got.Contains(i*7))
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	bytesData, err := New(100, 0.01).MarshalBinary()
+	require.NoError(t, err)
+	int64Data, err := NewInt64Bloom(100, 0.01).MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("incompatible", func(t *testing.T) {
+		var bf BloomFilter
+		assert.ErrorIs(t, bf.UnmarshalBinary(int64Data), ErrIncompatible)
+		var ibf Int64BloomFilter
+		assert.ErrorIs(t, ibf.UnmarshalBinary(bytesData), ErrIncompatible)
+
+		unknownHash := slices.Clone(bytesData)
+		unknownHash[2] = 9
+		assert.ErrorIs(t, bf.UnmarshalBinary(unknownHash), ErrIncompatible)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data []byte
+		}{
+			{"empty", nil},
+			{"bad version", []byte{9, kindBytes, hashMurmur3DoubleHashing, 1, 1}},
+			{"zero m", []byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 0, 1}},
+			{"zero k", []byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 8, 0}},
+			{"k above m", []byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 8, 9}},
+			{"k above limit", []byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 0x80, 0x01, maxHashFunctions + 1}},
+			{"huge k", []byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 0x80, 0x01, 0xff, 0xff, 0xff, 0xff, 0x0f}},
+			{"truncated", bytesData[:len(bytesData)-1]},
+			{"size mismatch", append([]byte{encodingVersion, kindBytes, hashMurmur3DoubleHashing, 100, 7}, bytesData[5:]...)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				bf := New(100, 0.01)
+				bf.Add([]byte("kept"))
+				assert.ErrorIs(t, bf.UnmarshalBinary(tt.data), ErrInvalidEncoding)
+				assert.True(t, bf.Contains([]byte("kept")), "failed unmarshal must not modify the filter")
+			})
+		}
+	})
+
+	t.Run("invalid bitmap", func(t *testing.T) {
+		var bf BloomFilter
+		err := bf.UnmarshalBinary(bytesData[:len(bytesData)-1])
+		assert.ErrorIs(t, err, ErrInvalidEncoding)
+		assert.ErrorIs(t, err, bitmap.ErrInvalidEncoding)
+	})
+
+	t.Run("non-local bitmap", func(t *testing.T) {
+		bf := NewWithBitmap(100, 0.01, func(size int) bitmap.Interface {
+			return bitmap.NewGrowableBitmap(size, 2*size)
+		})
+		_, err := bf.MarshalBinary()
+		assert.NoError(t, err, "growable bitmaps are local bitmaps")
+
+		bf = NewWithBitmap(100, 0.01, func(size int) bitmap.Interface {
+			return struct{ bitmap.Interface }{bitmap.NewBitmap(size)}
+		})
+		_, err = bf.MarshalBinary()
+		assert.Error(t, err)
+	})
+}
+
+func TestBloomFilterUnionIntersect(t *testing.T) {
+	a, b := New(1000, 0.01), New(1000, 0.01)
+	for i := 0; i < 300; i++ {
+		a.Add([]byte(fmt.Sprintf("a-%d", i)))
+		b.Add([]byte(fmt.Sprintf("b-%d", i)))
+	}
+	a.Add([]byte("both"))
+	b.Add([]byte("both"))
+
+	union := New(1000, 0.01)
+	require.NoError(t, union.Union(a))
+	require.NoError(t, union.Union(b))
+	for i := 0; i < 300; i++ {
+		assert.True(t, union.Contains([]byte(fmt.Sprintf("a-%d", i))))
+		assert.True(t, union.Contains([]byte(fmt.Sprintf("b-%d", i))))
+	}
+
+	require.NoError(t, a.Intersect(b))
+	assert.True(t, a.Contains([]byte("both")))
+	inA := 0
+	for i := 0; i < 300; i++ {
+		if a.Contains([]byte(fmt.Sprintf("a-%d", i))) {
+			inA++
+		}
+	}
+	assert.Less(t, inA, 30, "elements only in one filter are dropped")
+
+	assert.ErrorIs(t, a.Union(New(2000, 0.01)), ErrIncompatible)
+	assert.ErrorIs(t, a.Intersect(New(1000, 0.001)), ErrIncompatible)
+	other := NewWithBitmap(1000, 0.01, func(size int) bitmap.Interface {
+		return struct{ bitmap.Interface }{bitmap.NewBitmap(size)}
+	})
+	assert.ErrorIs(t, a.Union(other), ErrIncompatible)
+}
+
+func TestInt64BloomFilterUnionIntersect(t *testing.T) {
+	a, b := NewInt64Bloom(1000, 0.01), NewInt64Bloom(1000, 0.01)
+	a.AddMany([]int64{1, 2, 3})
+	b.AddMany([]int64{3, 4, 5})
+
+	require.NoError(t, a.Union(b))
+	for _, v := range []int64{1, 2, 3, 4, 5} {
+		assert.True(t, a.Contains(v))
+	}
+
+	c := NewInt64Bloom(1000, 0.01)
+	c.AddMany([]int64{3, 6})
+	require.NoError(t, a.Intersect(c))
+	assert.True(t, a.Contains(3))
+	assert.False(t, a.Contains(1))
+	assert.False(t, a.Contains(6))
+
+	assert.ErrorIs(t, a.Union(NewInt64Bloom(10, 0.01)), ErrIncompatible)
+}
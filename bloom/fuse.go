@@ -0,0 +1,333 @@
+package bloom
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	fuseArity            = 3
+	fuseMaxSegmentLength = 1 << 18
+	fuseMaxIterations    = 100
+)
+
+var ErrBuildFailed = errors.New("binary fuse filter construction failed")
+
+// BinaryFuse8 represents an immutable binary fuse filter with 8-bit fingerprints (Graf & Lemire, 2022).
+// It is built once from a key set, uses about 9 bits per key and has a false positive rate of 1/256 (0.4%).
+// It is safe for concurrent use as it cannot be modified.
+type BinaryFuse8 struct {
+	seed               uint64
+	segmentLength      uint32
+	segmentLengthMask  uint32
+	segmentCount       uint32
+	segmentCountLength uint32
+	fingerprints       []uint8
+}
+
+// NewBinaryFuse8 builds a binary fuse filter of keys, duplicate keys are allowed
+func NewBinaryFuse8(keys []uint64) (*BinaryFuse8, error) {
+	// the hashes of distinct keys are distinct, which the construction relies on
+	keys = slices.Compact(slices.Sorted(slices.Values(keys)))
+	f := &BinaryFuse8{}
+	f.initialize(uint32(len(keys)))
+	if err := f.populate(keys); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewBinaryFuse8FromBytes builds a binary fuse filter of []byte keys, looked up with ContainsBytes
+func NewBinaryFuse8FromBytes(keys [][]byte) (*BinaryFuse8, error) {
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i], _ = BytesHasher(key)
+	}
+	return NewBinaryFuse8(hashes)
+}
+
+// Contains check if the key may exist
+func (f *BinaryFuse8) Contains(key uint64) bool {
+	if len(f.fingerprints) == 0 {
+		return false
+	}
+	hash := fuseMix(key, f.seed)
+	h0, h1, h2 := f.positions(hash)
+	return fuseFingerprint(hash)^f.fingerprints[h0]^f.fingerprints[h1]^f.fingerprints[h2] == 0
+}
+
+// ContainsBytes check if the []byte key may exist in a filter built by NewBinaryFuse8FromBytes
+func (f *BinaryFuse8) ContainsBytes(key []byte) bool {
+	hash, _ := BytesHasher(key)
+	return f.Contains(hash)
+}
+
+// SizeInBytes returns the size of the fingerprint table
+func (f *BinaryFuse8) SizeInBytes() int {
+	return len(f.fingerprints)
+}
+
+// initialize computes the layout for size keys: the table is made of segmentCount+2 segments,
+// the three positions of a key are in three consecutive segments
+func (f *BinaryFuse8) initialize(size uint32) {
+	if size == 0 {
+		return
+	}
+
+	f.segmentLength = 4
+	if size > 1 {
+		exp := int(math.Floor(math.Log(float64(size))/math.Log(3.33) + 2.25))
+		f.segmentLength = min(uint32(1)<<exp, fuseMaxSegmentLength)
+	}
+	f.segmentLengthMask = f.segmentLength - 1
+
+	sizeFactor := 1.125
+	if size > 1 {
+		sizeFactor = max(1.125, 0.875+0.25*math.Log(1_000_000)/math.Log(float64(size)))
+	}
+	capacity := uint32(math.Round(float64(size) * sizeFactor))
+	segments := (capacity + f.segmentLength - 1) / f.segmentLength
+	f.segmentCount = 1
+	if segments > fuseArity {
+		f.segmentCount = segments - (fuseArity - 1)
+	}
+	f.segmentCountLength = f.segmentCount * f.segmentLength
+	f.fingerprints = make([]uint8, (f.segmentCount+fuseArity-1)*f.segmentLength)
+}
+
+// populate finds an assignment of fingerprints by peeling the 3-hypergraph of the keys,
+// retrying with another seed when the graph has a cycle
+func (f *BinaryFuse8) populate(keys []uint64) error {
+	size := uint32(len(keys))
+	if size == 0 {
+		return nil
+	}
+	capacity := uint32(len(f.fingerprints))
+
+	alone := make([]uint32, capacity)
+	// the lowest 2 bits are the index (0, 1 or 2) of the position, the others count the keys
+	t2count := make([]uint8, capacity)
+	t2hash := make([]uint64, capacity)
+	reverseH := make([]uint8, size)
+	reverseOrder := make([]uint64, size+1)
+	reverseOrder[size] = 1
+
+	blockBits := 1
+	for 1<<blockBits < f.segmentCount {
+		blockBits++
+	}
+	startPos := make([]uint32, 1<<blockBits)
+
+	var h012 [5]uint32
+	rng := uint64(1)
+	for iteration := 0; ; iteration++ {
+		if iteration >= fuseMaxIterations {
+			return errors.Wrapf(ErrBuildFailed, "no assignment found for %d keys", size)
+		}
+		f.seed = splitmix64(&rng)
+		clear(reverseOrder[:size])
+		clear(t2count)
+		clear(t2hash)
+
+		// sort the hashes roughly by segment for memory locality
+		for i := range startPos {
+			startPos[i] = uint32((uint64(i) * uint64(size)) >> blockBits)
+		}
+		for _, key := range keys {
+			hash := fuseMix(key, f.seed)
+			segment := hash >> (64 - blockBits)
+			for reverseOrder[startPos[segment]] != 0 {
+				segment = (segment + 1) & (1<<blockBits - 1)
+			}
+			reverseOrder[startPos[segment]] = hash
+			startPos[segment]++
+		}
+
+		failed := false
+		for _, hash := range reverseOrder[:size] {
+			h0, h1, h2 := f.positions(hash)
+			t2count[h0] += 4
+			t2hash[h0] ^= hash
+			t2count[h1] += 4
+			t2count[h1] ^= 1
+			t2hash[h1] ^= hash
+			t2count[h2] += 4
+			t2count[h2] ^= 2
+			t2hash[h2] ^= hash
+			// the 6-bit counter overflowed
+			failed = failed || t2count[h0] < 4 || t2count[h1] < 4 || t2count[h2] < 4
+		}
+		if failed {
+			continue
+		}
+
+		// peel positions holding a single key
+		queued := 0
+		for i := uint32(0); i < capacity; i++ {
+			alone[queued] = i
+			if t2count[i]>>2 == 1 {
+				queued++
+			}
+		}
+		stacked := uint32(0)
+		for queued > 0 {
+			queued--
+			index := alone[queued]
+			if t2count[index]>>2 != 1 {
+				continue
+			}
+			hash := t2hash[index]
+			found := t2count[index] & 3
+			reverseH[stacked] = found
+			reverseOrder[stacked] = hash
+			stacked++
+
+			h0, h1, h2 := f.positions(hash)
+			h012[0], h012[1], h012[2], h012[3], h012[4] = h0, h1, h2, h0, h1
+			for _, other := range [2]uint8{found + 1, found + 2} {
+				index := h012[other]
+				alone[queued] = index
+				if t2count[index]>>2 == 2 {
+					queued++
+				}
+				t2count[index] -= 4
+				t2count[index] ^= other % 3
+				t2hash[index] ^= hash
+			}
+		}
+		if stacked == size {
+			break
+		}
+	}
+
+	// assign fingerprints in reverse peeling order, so that each key owns a position not used by later keys
+	for i := int(size) - 1; i >= 0; i-- {
+		hash := reverseOrder[i]
+		h0, h1, h2 := f.positions(hash)
+		h012[0], h012[1], h012[2], h012[3], h012[4] = h0, h1, h2, h0, h1
+		found := reverseH[i]
+		f.fingerprints[h012[found]] = fuseFingerprint(hash) ^ f.fingerprints[h012[found+1]] ^ f.fingerprints[h012[found+2]]
+	}
+	return nil
+}
+
+// positions returns the three table positions of hash, one per consecutive segment
+func (f *BinaryFuse8) positions(hash uint64) (uint32, uint32, uint32) {
+	hi, _ := bits.Mul64(hash, uint64(f.segmentCountLength))
+	h0 := uint32(hi)
+	h1 := h0 + f.segmentLength
+	h2 := h1 + f.segmentLength
+	h1 ^= uint32(hash>>18) & f.segmentLengthMask
+	h2 ^= uint32(hash) & f.segmentLengthMask
+	return h0, h1, h2
+}
+
+func fuseFingerprint(hash uint64) uint8 {
+	return uint8(hash ^ hash>>32)
+}
+
+// fuseMix mixes a key with the seed using the murmur3 64-bit finalizer
+func fuseMix(key, seed uint64) uint64 {
+	h := key + seed
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// Binary format of a marshaled BinaryFuse8:
+//
+//	version         1 byte
+//	kind            1 byte
+//	hash            1 byte, the hashing scheme
+//	seed            8 bytes, little-endian
+//	segment length  uvarint
+//	segment count   uvarint
+//	fingerprints    (segment count + 2) * segment length bytes
+const (
+	kindBinaryFuse8 byte = 4
+
+	hashMurmur3Finalizer byte = 2 // keys mixed with the seed by the murmur3 finalizer
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BinaryFuse8)(nil)
+	_ encoding.BinaryUnmarshaler = (*BinaryFuse8)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (f *BinaryFuse8) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 11+2*binary.MaxVarintLen32+len(f.fingerprints))
+	data = append(data, encodingVersion, kindBinaryFuse8, hashMurmur3Finalizer)
+	data = binary.LittleEndian.AppendUint64(data, f.seed)
+	data = binary.AppendUvarint(data, uint64(f.segmentLength))
+	data = binary.AppendUvarint(data, uint64(f.segmentCount))
+	return append(data, f.fingerprints...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the filter by the decoded one.
+// It can be called on a zero BinaryFuse8, but must not be called concurrently with other methods.
+func (f *BinaryFuse8) UnmarshalBinary(data []byte) error {
+	if len(data) < 11 {
+		return errors.Wrap(ErrInvalidEncoding, "header too short")
+	}
+	if data[0] != encodingVersion {
+		return errors.Wrapf(ErrInvalidEncoding, "unsupported version %d", data[0])
+	}
+	if data[1] != kindBinaryFuse8 {
+		return errors.Wrapf(ErrIncompatible, "element kind %d, expected %d", data[1], kindBinaryFuse8)
+	}
+	if data[2] != hashMurmur3Finalizer {
+		return errors.Wrapf(ErrIncompatible, "unknown hashing scheme %d", data[2])
+	}
+	seed := binary.LittleEndian.Uint64(data[3:])
+	data = data[11:]
+
+	segmentLength, n := binary.Uvarint(data)
+	if n <= 0 || segmentLength > fuseMaxSegmentLength || segmentLength&(segmentLength-1) != 0 {
+		return errors.Wrap(ErrInvalidEncoding, "invalid segment length")
+	}
+	data = data[n:]
+	segmentCount, n := binary.Uvarint(data)
+	if n <= 0 || (segmentCount+fuseArity)*max(segmentLength, 1) > math.MaxUint32 {
+		return errors.Wrap(ErrInvalidEncoding, "invalid segment count")
+	}
+	data = data[n:]
+
+	decoded := BinaryFuse8{seed: seed}
+	if segmentLength != 0 {
+		if segmentCount == 0 {
+			return errors.Wrap(ErrInvalidEncoding, "invalid segment count")
+		}
+		decoded.segmentLength = uint32(segmentLength)
+		decoded.segmentLengthMask = uint32(segmentLength) - 1
+		decoded.segmentCount = uint32(segmentCount)
+		decoded.segmentCountLength = uint32(segmentCount * segmentLength)
+	} else if segmentCount != 0 {
+		return errors.Wrap(ErrInvalidEncoding, "invalid segment count")
+	}
+	if want := (segmentCount + fuseArity - 1) * segmentLength; uint64(len(data)) != want {
+		return errors.Wrapf(ErrInvalidEncoding, "%d fingerprints, expected %d", len(data), want)
+	}
+	if len(data) > 0 {
+		decoded.fingerprints = append([]uint8(nil), data...)
+	}
+
+	*f = decoded
+	return nil
+}
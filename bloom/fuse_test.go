@@ -0,0 +1,161 @@
+package bloom
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryFuse8(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 10, 100, 1000, 100_000} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(int64(size)))
+			keys := make([]uint64, size)
+			for i := range keys {
+				keys[i] = rnd.Uint64()
+			}
+
+			f, err := NewBinaryFuse8(keys)
+			require.NoError(t, err)
+			for _, key := range keys {
+				require.True(t, f.Contains(key), "no false negatives")
+			}
+			if size == 0 {
+				assert.False(t, f.Contains(42))
+			}
+		})
+	}
+}
+
+func TestBinaryFuse8FalsePositiveRate(t *testing.T) {
+	const n = 1_000_000
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = uint64(i)
+	}
+	f, err := NewBinaryFuse8(keys)
+	require.NoError(t, err)
+
+	bitsPerKey := float64(8*f.SizeInBytes()) / n
+	assert.Less(t, bitsPerKey, 9.2, "bits per key")
+
+	total, falsePositives := 1_000_000, 0
+	for i := 0; i < total; i++ {
+		if f.Contains(uint64(n + i)) {
+			falsePositives++
+		}
+	}
+	fpRate := float64(falsePositives) / float64(total)
+	assert.InDelta(t, 1.0/256, fpRate, 0.0005, "measured false positive rate %f", fpRate)
+}
+
+func TestBinaryFuse8Duplicates(t *testing.T) {
+	keys := []uint64{1, 2, 3, 2, 1, 1, 4}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, uint64(i%300))
+	}
+	f, err := NewBinaryFuse8(keys)
+	require.NoError(t, err)
+	for _, key := range keys {
+		assert.True(t, f.Contains(key))
+	}
+}
+
+func TestBinaryFuse8FromBytes(t *testing.T) {
+	names := [][]byte{[]byte("admin"), []byte("gm"), []byte("system"), []byte("")}
+	f, err := NewBinaryFuse8FromBytes(names)
+	require.NoError(t, err)
+	for _, name := range names {
+		assert.True(t, f.ContainsBytes(name))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10_000; i++ {
+		if f.ContainsBytes([]byte(fmt.Sprintf("player-%d", i))) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 100)
+}
+
+func TestBinaryFuse8MarshalBinary(t *testing.T) {
+	keys := make([]uint64, 5000)
+	for i := range keys {
+		keys[i] = uint64(i) * 7919
+	}
+	f, err := NewBinaryFuse8(keys)
+	require.NoError(t, err)
+
+	data, err := f.MarshalBinary()
+	require.NoError(t, err)
+
+	var got BinaryFuse8
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, f.SizeInBytes(), got.SizeInBytes())
+	for _, key := range keys {
+		assert.True(t, got.Contains(key))
+	}
+	for key := uint64(1); key < 1000; key++ {
+		assert.Equal(t, f.Contains(key), got.Contains(key))
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		empty, err := NewBinaryFuse8(nil)
+		require.NoError(t, err)
+		data, err := empty.MarshalBinary()
+		require.NoError(t, err)
+		got := f
+		require.NoError(t, got.UnmarshalBinary(data))
+		assert.False(t, got.Contains(keys[0]))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		bloomData, err := New(100, 0.01).MarshalBinary()
+		require.NoError(t, err)
+
+		var got BinaryFuse8
+		assert.ErrorIs(t, got.UnmarshalBinary(bloomData), ErrIncompatible)
+		assert.ErrorIs(t, got.UnmarshalBinary(nil), ErrInvalidEncoding)
+		assert.ErrorIs(t, got.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+		assert.ErrorIs(t, got.UnmarshalBinary(append(data[:11:11], 3, 1, 0, 0, 0)), ErrInvalidEncoding, "segment length must be a power of two")
+	})
+}
+
+func TestBinaryFuse8Concurrency(t *testing.T) {
+	keys := make([]uint64, 10_000)
+	for i := range keys {
+		keys[i] = uint64(i)
+	}
+	f, err := NewBinaryFuse8(keys)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, key := range keys {
+				assert.True(t, f.Contains(key))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkBinaryFuse8Contains(b *testing.B) {
+	keys := make([]uint64, benchmarkElements)
+	for i := range keys {
+		keys[i] = uint64(i)
+	}
+	f, err := NewBinaryFuse8(keys)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Contains(uint64(i))
+	}
+}
@@ -0,0 +1,84 @@
+package bloom
+
+import (
+	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
+)
+
+// Bloom represents a thread-safe Bloom filter of keys of type T hashed by a Hasher
+type Bloom[T any] struct {
+	bitmap bitmap.Interface
+	hash   Hasher[T]
+	kind   byte // element kind recorded by MarshalBinary
+	k      uint32
+	size   uint32
+}
+
+// NewBloom create bloom filter of keys hashed by hash, for example NewBloom(n, p, StringHasher)
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewBloom[T any](n uint32, p float64, hash Hasher[T]) *Bloom[T] {
+	return NewBloomWithBitmap(n, p, hash, newLocalBitmap)
+}
+
+// NewBloomWithBitmap create bloom filter of keys hashed by hash on the bitmap returned by newBitmap for the computed size.
+// On a bitmap.RedisBitmap, Contains reports true while Redis fails, a false positive rather than a false negative,
+// and the error is available from the Err method of the bitmap.
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewBloomWithBitmap[T any](n uint32, p float64, hash Hasher[T], newBitmap func(size int) bitmap.Interface) *Bloom[T] {
+	return newBloom(n, p, hash, kindCustom, newBitmap)
+}
+
+func newBloom[T any](n uint32, p float64, hash Hasher[T], kind byte, newBitmap func(size int) bitmap.Interface) *Bloom[T] {
+	m, k := estimateParameters(n, p)
+	return &Bloom[T]{
+		bitmap: newBitmap(int(m)),
+		hash:   hash,
+		kind:   kind,
+		k:      k,
+		size:   m,
+	}
+}
+
+// Add add element to bloom filter
+func (bf *Bloom[T]) Add(key T) {
+	bf.bitmap.MSet(bf.indexes(key))
+}
+
+// AddMany add multiple elements in a single bitmap update
+func (bf *Bloom[T]) AddMany(keys []T) {
+	indexes := make([]int, 0, len(keys)*int(bf.k))
+	for _, key := range keys {
+		h1, h2 := bf.hash(key)
+		indexes = appendIndexes(indexes, h1, h2, bf.k, bf.size)
+	}
+	bf.bitmap.MSet(indexes)
+}
+
+// Contains check if the element may exist
+func (bf *Bloom[T]) Contains(key T) bool {
+	if bs, ok := bf.bitmap.(bitmap.AllSetter); ok {
+		return bs.AllSet(bf.indexes(key))
+	}
+	for _, index := range bf.indexes(key) {
+		if !bf.bitmap.IsSet(index) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsMany check if each of the elements may exist
+func (bf *Bloom[T]) ContainsMany(keys []T) []bool {
+	result := make([]bool, len(keys))
+	for i, key := range keys {
+		result[i] = bf.Contains(key)
+	}
+	return result
+}
+
+// indexes returns the bit indexes of the element
+func (bf *Bloom[T]) indexes(key T) []int {
+	h1, h2 := bf.hash(key)
+	return appendIndexes(make([]int, 0, bf.k), h1, h2, bf.k, bf.size)
+}
@@ -0,0 +1,97 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomHashers(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		bf := NewBloom(1000, 0.01, StringHasher)
+		bf.Add("alice")
+		assert.True(t, bf.Contains("alice"))
+		assert.False(t, bf.Contains("bob"))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		bf := NewBloom(1000, 0.01, BytesHasher)
+		bf.Add([]byte("alice"))
+		assert.True(t, bf.Contains([]byte("alice")))
+		assert.False(t, bf.Contains([]byte("bob")))
+	})
+
+	t.Run("int64", func(t *testing.T) {
+		bf := NewBloom(1000, 0.01, Int64Hasher)
+		bf.Add(-42)
+		assert.True(t, bf.Contains(-42))
+		assert.False(t, bf.Contains(42))
+	})
+
+	t.Run("uint64", func(t *testing.T) {
+		bf := NewBloom(1000, 0.01, Uint64Hasher)
+		bf.Add(1 << 63)
+		assert.True(t, bf.Contains(1<<63))
+		assert.False(t, bf.Contains(1))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		type point struct{ x, y int32 }
+		bf := NewBloom(1000, 0.01, func(p point) (uint64, uint64) {
+			return Uint64Hasher(uint64(uint32(p.x))<<32 | uint64(uint32(p.y)))
+		})
+		bf.Add(point{1, 2})
+		assert.True(t, bf.Contains(point{1, 2}))
+		assert.False(t, bf.Contains(point{2, 1}))
+	})
+}
+
+func TestBloomAddMany(t *testing.T) {
+	keys := make([]string, 500)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	batch := NewBloom(1000, 0.01, StringHasher)
+	batch.AddMany(keys)
+	single := NewBloom(1000, 0.01, StringHasher)
+	for _, key := range keys {
+		single.Add(key)
+	}
+	assert.Equal(t, single.bitmap.Count(), batch.bitmap.Count(), "every probe of every element is set")
+
+	found := batch.ContainsMany(append(keys[:3:3], "missing"))
+	assert.Equal(t, []bool{true, true, true, false}, found)
+	assert.Empty(t, batch.ContainsMany(nil))
+
+	batch.AddMany(nil)
+	assert.Equal(t, single.bitmap.Count(), batch.bitmap.Count())
+}
+
+func TestBloomMarshalBinary(t *testing.T) {
+	bf := NewBloom(1000, 0.01, StringHasher)
+	bf.AddMany([]string{"a", "b", "c"})
+	data, err := bf.MarshalBinary()
+	require.NoError(t, err)
+
+	got := NewBloom(1, 0.5, StringHasher)
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, []bool{true, true, true, false}, got.ContainsMany([]string{"a", "b", "c", "d"}))
+
+	var zero Bloom[string]
+	assert.ErrorIs(t, zero.UnmarshalBinary(data), ErrIncompatible)
+	var bytesFilter BloomFilter
+	assert.ErrorIs(t, bytesFilter.UnmarshalBinary(data), ErrIncompatible, "custom filters do not load into typed ones")
+}
+
+func TestWrappersShareBloom(t *testing.T) {
+	bf := New(1000, 0.01)
+	bf.AddMany([][]byte{[]byte("a"), []byte("b")})
+	assert.Equal(t, []bool{true, false}, bf.ContainsMany([][]byte{[]byte("a"), []byte("z")}))
+
+	ibf := NewInt64Bloom(1000, 0.01)
+	ibf.AddMany([]int64{1, 2})
+	assert.Equal(t, []bool{true, false}, ibf.ContainsMany([]int64{2, 3}))
+}
@@ -0,0 +1,45 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"unsafe"
+
+	"github.com/spaolacci/murmur3"
+)
+
+// Bit indexes are derived with Kirsch–Mitzenmacher double hashing: the i-th probe of an element is
+// (h1 + i*h2) mod m, where h1 and h2 are the two halves of a single 128-bit hash.
+// The hashing keeps no state, so filters can hash concurrently without synchronization.
+
+// Hasher returns the two 64-bit base hashes of a key, it must be safe for concurrent use
+type Hasher[T any] func(key T) (uint64, uint64)
+
+// BytesHasher hashes a []byte key with 128-bit murmur3
+func BytesHasher(key []byte) (uint64, uint64) {
+	return murmur3.Sum128(key)
+}
+
+// StringHasher hashes a string key with 128-bit murmur3, like BytesHasher hashes the same bytes
+func StringHasher(key string) (uint64, uint64) {
+	return murmur3.Sum128(unsafe.Slice(unsafe.StringData(key), len(key)))
+}
+
+// Int64Hasher hashes an int64 key with 128-bit murmur3 over its little-endian bytes
+func Int64Hasher(key int64) (uint64, uint64) {
+	return Uint64Hasher(uint64(key))
+}
+
+// Uint64Hasher hashes a uint64 key with 128-bit murmur3 over its little-endian bytes
+func Uint64Hasher(key uint64) (uint64, uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], key)
+	return murmur3.Sum128(buf[:])
+}
+
+// appendIndexes appends the k bit indexes of the element with base hashes h1 and h2 to indexes
+func appendIndexes(indexes []int, h1, h2 uint64, k, m uint32) []int {
+	for i := uint64(0); i < uint64(k); i++ {
+		indexes = append(indexes, int((h1+i*h2)%uint64(m)))
+	}
+	return indexes
+}
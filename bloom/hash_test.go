@@ -0,0 +1,64 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendIndexes(t *testing.T) {
+	h1, h2 := BytesHasher([]byte("hello"))
+	indexes := appendIndexes(nil, h1, h2, 20, 1000)
+	assert.Len(t, indexes, 20, "k is not capped")
+	for i, index := range indexes {
+		assert.Less(t, index, 1000)
+		assert.GreaterOrEqual(t, index, 0)
+		assert.Equal(t, int((h1+uint64(i)*h2)%1000), index)
+	}
+
+	// appending keeps the previous indexes
+	more := appendIndexes(indexes, h1, h2, 3, 1000)
+	assert.Equal(t, indexes, more[:20])
+	assert.Equal(t, indexes[:3], more[20:])
+
+	a1, a2 := Int64Hasher(1)
+	b1, b2 := Int64Hasher(2)
+	assert.NotEqual(t, a1, b1)
+	assert.NotEqual(t, a2, b2)
+}
+
+func TestHashers(t *testing.T) {
+	b1, b2 := BytesHasher([]byte("hello"))
+	s1, s2 := StringHasher("hello")
+	assert.Equal(t, b1, s1, "strings hash like their bytes")
+	assert.Equal(t, b2, s2)
+
+	e1, e2 := StringHasher("")
+	z1, z2 := BytesHasher(nil)
+	assert.Equal(t, z1, e1)
+	assert.Equal(t, z2, e2)
+
+	i1, i2 := Int64Hasher(-1)
+	u1, u2 := Uint64Hasher(1<<64 - 1)
+	assert.Equal(t, u1, i1, "int64 hashes like its two's complement")
+	assert.Equal(t, u2, i2)
+}
+
+func TestEstimateParameters(t *testing.T) {
+	tests := []struct {
+		n    uint32
+		p    float64
+		m, k uint32
+	}{
+		{1000, 0.01, 9586, 7},
+		{1000, 0.0001, 19171, 14},
+		{1000, 0.1, 4793, 4},
+		{0, 0.01, 10, 7},
+		{1, 1e-30, 144, maxHashFunctions},
+	}
+	for _, tt := range tests {
+		m, k := estimateParameters(tt.n, tt.p)
+		assert.Equal(t, tt.m, m, "n=%d p=%f", tt.n, tt.p)
+		assert.Equal(t, tt.k, k, "n=%d p=%f", tt.n, tt.p)
+	}
+}
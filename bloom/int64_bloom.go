@@ -4,82 +4,44 @@ import (
 	"github.com/vulcan-frame/vulcan-pkg-tool/bitmap"
 )
 
-// Int64BloomFilter optimized Bloom filter for int64
+// Int64BloomFilter represents a thread-safe Bloom filter of int64 elements
 type Int64BloomFilter struct {
-	bitmap   *bitmap.Bitmap
-	hashFunc []func(int64) uint32
-	size     uint32
+	*Bloom[int64]
 }
 
-// NewInt64Bloom create int64 optimized Bloom filter
+// NewInt64Bloom create int64 Bloom filter
 // n: expected element count
 // p: expected false positive rate (0 < p < 1)
 func NewInt64Bloom(n uint32, p float64) *Int64BloomFilter {
-	m, k := estimateParameters(n, p)
-	// limit max hash function count to 8
-	if k > 8 {
-		k = 8
-	}
-	return &Int64BloomFilter{
-		bitmap:   bitmap.NewBitmap(int(m)),
-		hashFunc: createInt64HashFunctions(k),
-		size:     m,
-	}
+	return NewInt64BloomWithBitmap(n, p, newLocalBitmap)
 }
 
-// Add add int64 element
-func (bf *Int64BloomFilter) Add(data int64) {
-	for _, fn := range bf.hashFunc {
-		h := fn(data) % bf.size
-		bf.bitmap.Set(int(h))
-	}
+// NewInt64BloomWithBitmap create int64 Bloom filter on the bitmap returned by newBitmap for the computed size,
+// for example a bitmap.RedisBitmap shared by every server of a zone.
+// On a bitmap.RedisBitmap, Contains reports true while Redis fails, a false positive rather than a false negative,
+// and the error is available from the Err method of the bitmap.
+// n: expected element count
+// p: expected false positive rate (0 < p < 1)
+func NewInt64BloomWithBitmap(n uint32, p float64, newBitmap func(size int) bitmap.Interface) *Int64BloomFilter {
+	return &Int64BloomFilter{newBloom(n, p, Int64Hasher, kindInt64, newBitmap)}
 }
 
-// AddMany add multiple int64 elements
-func (bf *Int64BloomFilter) AddMany(data []int64) {
-	indexes := make([]int, len(data))
-	for i, d := range data {
-		for _, fn := range bf.hashFunc {
-			h := fn(d) % bf.size
-			indexes[i] = int(h)
-		}
-	}
-	bf.bitmap.MSet(indexes)
+// Union adds the elements of other to the filter.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *Int64BloomFilter) Union(other *Int64BloomFilter) error {
+	return bf.Bloom.Union(other.Bloom)
 }
 
-// Contains check if the element may exist
-func (bf *Int64BloomFilter) Contains(data int64) bool {
-	for _, fn := range bf.hashFunc {
-		h := fn(data) % bf.size
-		if !bf.bitmap.IsSet(int(h)) {
-			return false
-		}
-	}
-	return true
+// Intersect keeps in the filter only the elements that may also exist in other.
+// Both filters must have the same parameters and use process-local bitmaps.
+func (bf *Int64BloomFilter) Intersect(other *Int64BloomFilter) error {
+	return bf.Bloom.Intersect(other.Bloom)
 }
 
-// create int64 optimized hash functions
-func createInt64HashFunctions(k uint32) []func(int64) uint32 {
-	base := []func(int64) uint32{
-		func(data int64) uint32 { // use high bits
-			return uint32(uint64(data) >> 32)
-		},
-		func(data int64) uint32 { // use low bits
-			return uint32(data)
-		},
-		func(data int64) uint32 { // mix bits
-			return uint32(uint64(data)>>16) ^ uint32(data)
-		},
-	}
-
-	// generate more hash functions using double hash technique
-	fns := make([]func(int64) uint32, 0, k)
-	for i := uint32(0); i < k; i++ {
-		idx := i % uint32(len(base))
-		factor := i/uint32(len(base)) + 1
-		fns = append(fns, func(data int64) uint32 {
-			return base[idx](data) * uint32(factor)
-		})
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it can be called on a zero Int64BloomFilter
+func (bf *Int64BloomFilter) UnmarshalBinary(data []byte) error {
+	if bf.Bloom == nil {
+		bf.Bloom = &Bloom[int64]{hash: Int64Hasher, kind: kindInt64}
 	}
-	return fns
+	return bf.Bloom.UnmarshalBinary(data)
 }
@@ -50,6 +50,38 @@ func TestInt64EdgeCases(t *testing.T) {
 	})
 }
 
+func TestInt64FalsePositiveRate(t *testing.T) {
+	const n, p = 20_000, 0.01
+	bf := NewInt64Bloom(n, p)
+	for i := int64(0); i < n; i++ {
+		bf.Add(i)
+	}
+
+	total, falsePositives := 100_000, 0
+	for i := 0; i < total; i++ {
+		if bf.Contains(int64(n + i)) {
+			falsePositives++
+		}
+	}
+	fpRate := float64(falsePositives) / float64(total)
+	assert.InDelta(t, p, fpRate, p*0.3, "measured false positive rate %f", fpRate)
+}
+
+func TestInt64AddMany(t *testing.T) {
+	bf := NewInt64Bloom(1000, 0.01)
+	data := []int64{1, 2, 3, -7, 1 << 40}
+	bf.AddMany(data)
+	for _, d := range data {
+		assert.True(t, bf.Contains(d))
+	}
+
+	single := NewInt64Bloom(1000, 0.01)
+	for _, d := range data {
+		single.Add(d)
+	}
+	assert.Equal(t, single.bitmap.Count(), bf.bitmap.Count(), "AddMany sets the same bits as Add")
+}
+
 func BenchmarkInt64Bloom(b *testing.B) {
 	bf := NewInt64Bloom(1000000, 0.01)
 	data := make([]int64, b.N)
@@ -0,0 +1,127 @@
+package bloom
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultRotatingGenerations = 2
+
+// RotatingOptions configures a RotatingBloomFilter
+type RotatingOptions struct {
+	// Interval is the time between two rotations, it must be positive
+	Interval time.Duration
+	// Generations is the number of live generations, DefaultRotatingGenerations if not positive
+	Generations int
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+}
+
+// RotatingBloomFilter represents a thread-safe Bloom filter that forgets old elements.
+// Elements are added to the current generation, and every interval the oldest generation is dropped
+// and a new empty one becomes current. An element is remembered for at least (generations-1)*interval
+// and at most generations*interval.
+//
+// Rotation happens lazily on the next call after an interval elapsed, no goroutine is involved.
+type RotatingBloomFilter struct {
+	mutex       sync.RWMutex
+	generations []*BloomFilter // the current generation first
+	rotatedAt   time.Time
+
+	n        uint32
+	p        float64
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewRotating create rotating bloom filter
+// n: expected element count per interval
+// p: expected false positive rate (0 < p < 1), shared between the generations
+func NewRotating(n uint32, p float64, opts RotatingOptions) *RotatingBloomFilter {
+	if opts.Interval <= 0 {
+		panic("rotating bloom filter interval must be positive")
+	}
+	count := DefaultRotatingGenerations
+	if opts.Generations > 0 {
+		count = opts.Generations
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+
+	bf := &RotatingBloomFilter{
+		generations: make([]*BloomFilter, count),
+		rotatedAt:   now(),
+		n:           n,
+		// an element is looked up in every generation, so their false positive rates add up
+		p:        p / float64(count),
+		interval: opts.Interval,
+		now:      now,
+	}
+	for i := range bf.generations {
+		bf.generations[i] = New(bf.n, bf.p)
+	}
+	return bf
+}
+
+// Add add element to the current generation
+func (bf *RotatingBloomFilter) Add(data []byte) {
+	bf.advance()
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+	bf.generations[0].Add(data)
+}
+
+// Contains check if the element may exist in a live generation
+func (bf *RotatingBloomFilter) Contains(data []byte) bool {
+	bf.advance()
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+	for _, g := range bf.generations {
+		if g.Contains(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rotate drops the oldest generation immediately, the next rotation happens an interval later
+func (bf *RotatingBloomFilter) Rotate() {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+	bf.rotate(1)
+	bf.rotatedAt = bf.now()
+}
+
+// Generations returns the number of live generations
+func (bf *RotatingBloomFilter) Generations() int {
+	return len(bf.generations)
+}
+
+// advance rotates once for every interval elapsed since the last rotation
+func (bf *RotatingBloomFilter) advance() {
+	bf.mutex.RLock()
+	due := bf.now().Sub(bf.rotatedAt) >= bf.interval
+	bf.mutex.RUnlock()
+	if !due {
+		return
+	}
+
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+	elapsed := bf.now().Sub(bf.rotatedAt) / bf.interval
+	if elapsed <= 0 {
+		return // rotated by another goroutine
+	}
+	bf.rotate(int(min(elapsed, time.Duration(len(bf.generations)))))
+	bf.rotatedAt = bf.rotatedAt.Add(elapsed * bf.interval)
+}
+
+// rotate replaces the count oldest generations by new ones
+func (bf *RotatingBloomFilter) rotate(count int) {
+	for i := 0; i < count; i++ {
+		copy(bf.generations[1:], bf.generations)
+		bf.generations[0] = New(bf.n, bf.p)
+	}
+}
@@ -0,0 +1,133 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRotatingBloomFilter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	bf := NewRotating(1000, 0.01, RotatingOptions{
+		Interval:    time.Minute,
+		Generations: 3,
+		Now:         clock.Now,
+	})
+	assert.Equal(t, 3, bf.Generations())
+
+	bf.Add([]byte("packet-1"))
+	assert.True(t, bf.Contains([]byte("packet-1")))
+	assert.False(t, bf.Contains([]byte("packet-2")))
+
+	clock.Advance(59 * time.Second)
+	assert.True(t, bf.Contains([]byte("packet-1")))
+
+	clock.Advance(time.Second)
+	bf.Add([]byte("packet-2"))
+	clock.Advance(time.Minute)
+	assert.True(t, bf.Contains([]byte("packet-1")), "still in the oldest generation")
+	assert.True(t, bf.Contains([]byte("packet-2")))
+
+	clock.Advance(time.Minute)
+	assert.False(t, bf.Contains([]byte("packet-1")), "forgotten after generations*interval")
+	assert.True(t, bf.Contains([]byte("packet-2")))
+
+	// a long pause drops every generation
+	clock.Advance(time.Hour)
+	assert.False(t, bf.Contains([]byte("packet-2")))
+}
+
+func TestRotatingBloomFilterCatchesUp(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	bf := NewRotating(100, 0.01, RotatingOptions{Interval: time.Second, Now: clock.Now})
+	assert.Equal(t, DefaultRotatingGenerations, bf.Generations())
+
+	bf.Add([]byte("a"))
+	clock.Advance(1500 * time.Millisecond)
+	bf.Add([]byte("b"))
+
+	// the rotation schedule is kept, the next rotation happens at 2s and not 2.5s
+	clock.Advance(500 * time.Millisecond)
+	assert.False(t, bf.Contains([]byte("a")))
+	assert.True(t, bf.Contains([]byte("b")))
+
+	// the clock going backwards does not rotate
+	clock.Advance(-10 * time.Second)
+	assert.True(t, bf.Contains([]byte("b")))
+}
+
+func TestRotatingBloomFilterRotate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	bf := NewRotating(100, 0.01, RotatingOptions{Interval: time.Minute, Now: clock.Now})
+	bf.Add([]byte("a"))
+	bf.Rotate()
+	assert.True(t, bf.Contains([]byte("a")))
+	bf.Rotate()
+	assert.False(t, bf.Contains([]byte("a")))
+
+	assert.Panics(t, func() { NewRotating(100, 0.01, RotatingOptions{}) })
+}
+
+func TestRotatingFalsePositiveRate(t *testing.T) {
+	const n, p = 10_000, 0.01
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	bf := NewRotating(n, p, RotatingOptions{Interval: time.Minute, Generations: 4, Now: clock.Now})
+	for g := 0; g < 4; g++ {
+		for i := 0; i < n; i++ {
+			bf.Add([]byte(fmt.Sprintf("member-%d-%d", g, i)))
+		}
+		clock.Advance(time.Minute)
+		bf.Rotate()
+	}
+
+	total, falsePositives := 100_000, 0
+	for i := 0; i < total; i++ {
+		if bf.Contains([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	fpRate := float64(falsePositives) / float64(total)
+	assert.Less(t, fpRate, p*1.3, "measured false positive rate %f", fpRate)
+}
+
+func TestRotatingBloomFilterConcurrency(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	bf := NewRotating(10_000, 0.01, RotatingOptions{Interval: time.Second, Now: clock.Now})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10_000; i += 8 {
+				if i%1000 == 0 {
+					clock.Advance(100 * time.Millisecond)
+				}
+				bf.Add([]byte(fmt.Sprintf("key-%d", i)))
+				_ = bf.Contains([]byte(fmt.Sprintf("key-%d", i)))
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, DefaultRotatingGenerations, bf.Generations())
+}
@@ -0,0 +1,90 @@
+package bloom
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	scalableGrowth     = 2   // capacity ratio between two consecutive layers
+	scalableTightening = 0.8 // false positive rate ratio between two consecutive layers
+)
+
+// ScalableBloomFilter represents a thread-safe Bloom filter growing with the number of elements.
+// It chains layers of BloomFilter: when the last layer reaches its capacity a new layer twice as large
+// and with a tighter false positive rate is added, so that the overall rate stays below p.
+type ScalableBloomFilter struct {
+	mutex  sync.RWMutex
+	layers []*scalableLayer
+	p      float64
+}
+
+type scalableLayer struct {
+	filter   *BloomFilter
+	capacity uint32
+	count    uint32
+}
+
+// NewScalable create scalable bloom filter
+// n: expected element count of the first layer
+// p: expected false positive rate (0 < p < 1)
+func NewScalable(n uint32, p float64) *ScalableBloomFilter {
+	bf := &ScalableBloomFilter{p: p}
+	bf.addLayer(max(n, 1))
+	return bf
+}
+
+// Add add element to bloom filter
+func (bf *ScalableBloomFilter) Add(data []byte) {
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	// elements that may already exist do not count towards the capacity
+	if bf.contains(data) {
+		return
+	}
+
+	last := bf.layers[len(bf.layers)-1]
+	if last.count >= last.capacity {
+		capacity := uint32(min(uint64(last.capacity)*scalableGrowth, math.MaxUint32))
+		last = bf.addLayer(capacity)
+	}
+	last.filter.Add(data)
+	last.count++
+}
+
+// Contains check if the element may exist
+func (bf *ScalableBloomFilter) Contains(data []byte) bool {
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+	return bf.contains(data)
+}
+
+// Layers returns the number of layers
+func (bf *ScalableBloomFilter) Layers() int {
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+	return len(bf.layers)
+}
+
+func (bf *ScalableBloomFilter) contains(data []byte) bool {
+	// recent layers hold most of the elements
+	for i := len(bf.layers) - 1; i >= 0; i-- {
+		if bf.layers[i].filter.Contains(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// addLayer appends a layer of the given capacity.
+// Layer i has a false positive rate of p*(1-r)*r^i, which sums up to p over all layers.
+func (bf *ScalableBloomFilter) addLayer(capacity uint32) *scalableLayer {
+	p := bf.p * (1 - scalableTightening) * math.Pow(scalableTightening, float64(len(bf.layers)))
+	layer := &scalableLayer{
+		filter:   New(capacity, p),
+		capacity: capacity,
+	}
+	bf.layers = append(bf.layers, layer)
+	return layer
+}
@@ -0,0 +1,85 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalableBloomFilter(t *testing.T) {
+	bf := NewScalable(100, 0.01)
+	assert.Equal(t, 1, bf.Layers())
+	assert.False(t, bf.Contains([]byte("hello")))
+
+	bf.Add([]byte("hello"))
+	assert.True(t, bf.Contains([]byte("hello")))
+
+	// capacities are 100, 200, 400...
+	for i := 0; i < 100; i++ {
+		bf.Add([]byte(fmt.Sprintf("key-%d", i)))
+	}
+	assert.Equal(t, 2, bf.Layers())
+	for i := 0; i < 250; i++ {
+		bf.Add([]byte(fmt.Sprintf("more-%d", i)))
+	}
+	assert.Equal(t, 3, bf.Layers())
+
+	assert.True(t, bf.Contains([]byte("hello")))
+	for i := 0; i < 100; i++ {
+		assert.True(t, bf.Contains([]byte(fmt.Sprintf("key-%d", i))))
+	}
+}
+
+func TestScalableBloomFilterDuplicates(t *testing.T) {
+	bf := NewScalable(10, 0.01)
+	for i := 0; i < 1000; i++ {
+		bf.Add([]byte("same"))
+	}
+	assert.Equal(t, 1, bf.Layers(), "duplicates do not grow the filter")
+}
+
+func TestScalableFalsePositiveRate(t *testing.T) {
+	const p = 0.01
+	bf := NewScalable(1000, p)
+	// 100 times the initial capacity
+	for i := 0; i < 100_000; i++ {
+		bf.Add([]byte(fmt.Sprintf("member-%d", i)))
+	}
+	assert.Greater(t, bf.Layers(), 5)
+
+	total, falsePositives := 100_000, 0
+	for i := 0; i < total; i++ {
+		if bf.Contains([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	fpRate := float64(falsePositives) / float64(total)
+	assert.Less(t, fpRate, p, "measured false positive rate %f", fpRate)
+
+	// a plain filter sized for the initial capacity would be useless by now
+	plain := New(1000, p)
+	for i := 0; i < 100_000; i++ {
+		plain.Add([]byte(fmt.Sprintf("member-%d", i)))
+	}
+	assert.True(t, plain.Contains([]byte("other-0")))
+}
+
+func TestScalableBloomFilterConcurrency(t *testing.T) {
+	bf := NewScalable(100, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 10_000; i += 8 {
+				data := []byte(fmt.Sprintf("key-%d", i))
+				bf.Add(data)
+				assert.True(t, bf.Contains(data))
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Greater(t, bf.Layers(), 1)
+}
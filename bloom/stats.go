@@ -0,0 +1,44 @@
+package bloom
+
+import (
+	"math"
+)
+
+// M returns the number of bits of the filter
+func (bf *Bloom[T]) M() uint32 {
+	return bf.size
+}
+
+// K returns the number of hash functions of the filter
+func (bf *Bloom[T]) K() uint32 {
+	return bf.k
+}
+
+// FillRatio returns the ratio of bits set to 1
+func (bf *Bloom[T]) FillRatio() float64 {
+	return fillRatio(bf.bitmap.Count(), bf.size)
+}
+
+// EstimatedCount estimates the number of distinct elements added from the number of bits set,
+// it returns math.MaxInt when every bit is set
+func (bf *Bloom[T]) EstimatedCount() int {
+	return estimateCount(bf.bitmap.Count(), bf.size, bf.k)
+}
+
+// CurrentFalsePositiveRate returns the false positive rate for the current fill ratio,
+// which exceeds the configured rate once more than the expected element count were added
+func (bf *Bloom[T]) CurrentFalsePositiveRate() float64 {
+	return math.Pow(bf.FillRatio(), float64(bf.k))
+}
+
+func fillRatio(set int, m uint32) float64 {
+	return float64(set) / float64(m)
+}
+
+// estimateCount implements the Swamidass & Baldi estimator n = -(m/k) ln(1 - X/m), X being the bits set
+func estimateCount(set int, m, k uint32) int {
+	if set >= int(m) {
+		return math.MaxInt
+	}
+	return int(math.Round(-float64(m) / float64(k) * math.Log1p(-float64(set)/float64(m))))
+}
@@ -0,0 +1,56 @@
+package bloom
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilterStats(t *testing.T) {
+	bf := New(10_000, 0.01)
+	assert.Equal(t, uint32(95851), bf.M())
+	assert.Equal(t, uint32(7), bf.K())
+	assert.Equal(t, 0.0, bf.FillRatio())
+	assert.Equal(t, 0, bf.EstimatedCount())
+	assert.Equal(t, 0.0, bf.CurrentFalsePositiveRate())
+
+	for i := 0; i < 5000; i++ {
+		bf.Add([]byte(fmt.Sprintf("key-%d", i)))
+	}
+	assert.InDelta(t, 5000, bf.EstimatedCount(), 100)
+	assert.Less(t, bf.CurrentFalsePositiveRate(), 0.01)
+
+	for i := 5000; i < 10_000; i++ {
+		bf.Add([]byte(fmt.Sprintf("key-%d", i)))
+	}
+	assert.InDelta(t, 10_000, bf.EstimatedCount(), 200)
+	assert.InDelta(t, 0.5, bf.FillRatio(), 0.02, "a full filter has about half its bits set")
+	assert.InDelta(t, 0.01, bf.CurrentFalsePositiveRate(), 0.002)
+
+	// overloaded
+	for i := 10_000; i < 30_000; i++ {
+		bf.Add([]byte(fmt.Sprintf("key-%d", i)))
+	}
+	assert.InDelta(t, 30_000, bf.EstimatedCount(), 1000)
+	assert.Greater(t, bf.CurrentFalsePositiveRate(), 0.2)
+}
+
+func TestInt64BloomFilterStats(t *testing.T) {
+	bf := NewInt64Bloom(1000, 0.01)
+	assert.Equal(t, uint32(9586), bf.M())
+	assert.Equal(t, uint32(7), bf.K())
+
+	for i := int64(0); i < 1000; i++ {
+		bf.Add(i)
+	}
+	assert.InDelta(t, 1000, bf.EstimatedCount(), 50)
+	assert.InDelta(t, 0.5, bf.FillRatio(), 0.03)
+	assert.InDelta(t, 0.01, bf.CurrentFalsePositiveRate(), 0.003)
+}
+
+func TestEstimateCountSaturated(t *testing.T) {
+	assert.Equal(t, math.MaxInt, estimateCount(100, 100, 3))
+	assert.Equal(t, 0, estimateCount(0, 100, 3))
+}
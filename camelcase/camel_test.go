@@ -20,6 +20,8 @@ func TestToUpperCamel(t *testing.T) {
 		{"Mixed case", "mySQL_Query", "MySQLQuery"},
 		{"With numbers", "user_id_2", "UserID2"},
 		{"Abbreviations", "http_request", "HTTPRequest"},
+		{"Plural abbreviation", "player_ids", "PlayerIDs"},
+		{"Plural abbreviation only", "urls", "URLs"},
 		{"Unicode", "こんにちは_世界", "こんにちは世界"},
 	}
 
@@ -45,6 +47,8 @@ func TestToLowerCamel(t *testing.T) {
 		{"With numbers", "USER_ID_2", "userID2"},
 		{"Abbreviations", "HTTP_REQUEST", "httpRequest"},
 		{"Mixed case", "MySQL_Query", "mysqlQuery"},
+		{"Plural abbreviation", "URLs", "urls"},
+		{"Plural abbreviation suffix", "user_ids", "userIDs"},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +75,10 @@ func TestToUnderScore(t *testing.T) {
 		{"Abbreviations", "HTTPRequest", "http_request"},
 		{"Mixed case", "MySQLQuery", "mysql_query"},
 		{"Consecutive caps", "MySSHKey", "my_ssh_key"},
+		{"Plural abbreviation", "userIDs", "user_ids"},
+		{"Plural abbreviation only", "URLs", "urls"},
+		{"Plural unknown abbreviation", "NPCs", "npcs"},
+		{"Plural abbreviation in field", "PlayerIDsList", "player_ids_list"},
 		{"Unicode", "こんにちはWorld", "こんにちは_world"},
 	}
 
@@ -0,0 +1,103 @@
+package camelcase
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Converter converts identifiers between cases, writing its initialisms (ID, HTTP...) in their canonical form.
+// It is safe for concurrent use, including while initialisms are added or removed.
+type Converter struct {
+	mutex sync.Mutex // serializes updates
+	state atomic.Pointer[converterState]
+}
+
+// converterState is an immutable initialism set with its lookup table
+type converterState struct {
+	initialisms []string          // longest first
+	canonical   map[string]string // lower case initialism -> canonical form
+	maxLen      int               // length in bytes of the longest initialism
+}
+
+// NewConverter creates a converter with the given initialisms
+func NewConverter(initialisms ...string) *Converter {
+	c := &Converter{}
+	c.state.Store(newConverterState(nil, initialisms, nil))
+	return c
+}
+
+// AddInitialisms adds initialisms in their canonical form, such as "NPC" or "MySQL".
+// An initialism already present is replaced by the new form.
+func (c *Converter) AddInitialisms(initialisms ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.Store(newConverterState(c.state.Load().initialisms, initialisms, nil))
+}
+
+// RemoveInitialisms removes initialisms, ignoring case
+func (c *Converter) RemoveInitialisms(initialisms ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.Store(newConverterState(c.state.Load().initialisms, nil, initialisms))
+}
+
+// Initialisms returns the initialisms of the converter, longest first
+func (c *Converter) Initialisms() []string {
+	return slices.Clone(c.state.Load().initialisms)
+}
+
+// ToUpperCamel converts s to UpperCamelCase
+func (c *Converter) ToUpperCamel(s string) string {
+	return c.Convert(s, UpperCamel)
+}
+
+// ToLowerCamel converts s to lowerCamelCase
+func (c *Converter) ToLowerCamel(s string) string {
+	return c.Convert(s, LowerCamel)
+}
+
+// ToUnderScore converts s to snake_case
+func (c *Converter) ToUnderScore(s string) string {
+	return c.Convert(s, Snake)
+}
+
+// newConverterState returns the state of the initialisms of current with added and without removed
+func newConverterState(current, added, removed []string) *converterState {
+	initialisms := make([]string, 0, len(current)+len(added))
+	for _, abbr := range current {
+		if !slices.ContainsFunc(removed, equalFold(abbr)) {
+			initialisms = append(initialisms, abbr)
+		}
+	}
+	for _, abbr := range added {
+		if abbr == "" {
+			continue
+		}
+		if i := slices.IndexFunc(initialisms, equalFold(abbr)); i >= 0 {
+			initialisms[i] = abbr
+		} else {
+			initialisms = append(initialisms, abbr)
+		}
+	}
+	slices.SortStableFunc(initialisms, func(a, b string) int {
+		return len([]rune(b)) - len([]rune(a))
+	})
+
+	state := &converterState{
+		initialisms: initialisms,
+		canonical:   make(map[string]string, len(initialisms)),
+	}
+	for _, abbr := range initialisms {
+		state.canonical[strings.ToLower(abbr)] = abbr
+		state.maxLen = max(state.maxLen, len(abbr))
+	}
+
+	return state
+}
+
+// equalFold returns a function reporting whether its argument equals s, ignoring case
+func equalFold(s string) func(string) bool {
+	return func(v string) bool { return strings.EqualFold(v, s) }
+}
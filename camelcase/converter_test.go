@@ -0,0 +1,157 @@
+package camelcase
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConverterInitialisms(t *testing.T) {
+	c := NewConverter("ID", "PVP", "NPC", "GM", "MMR", "SKU")
+
+	tests := []struct {
+		name  string
+		input string
+		upper string
+		lower string
+	}{
+		{"PVP", "pvp_rank", "PVPRank", "pvpRank"},
+		{"NPC", "npc_id", "NPCID", "npcID"},
+		{"GM", "gm_command", "GMCommand", "gmCommand"},
+		{"MMR", "player_mmr", "PlayerMMR", "playerMMR"},
+		{"SKU", "item_sku_2", "ItemSKU2", "itemSKU2"},
+		{"Unknown", "http_request", "HttpRequest", "httpRequest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.ToUpperCamel(tt.input); got != tt.upper {
+				t.Errorf("ToUpperCamel(%q) = %q, want %q", tt.input, got, tt.upper)
+			}
+			if got := c.ToLowerCamel(tt.input); got != tt.lower {
+				t.Errorf("ToLowerCamel(%q) = %q, want %q", tt.input, got, tt.lower)
+			}
+			if got := c.ToUnderScore(tt.upper); got != tt.input {
+				t.Errorf("ToUnderScore(%q) = %q, want %q", tt.upper, got, tt.input)
+			}
+		})
+	}
+}
+
+func TestConverterAddRemoveInitialisms(t *testing.T) {
+	c := NewConverter("ID")
+	if got := c.ToUpperCamel("npc_id"); got != "NpcID" {
+		t.Errorf("ToUpperCamel before add = %q, want %q", got, "NpcID")
+	}
+
+	c.AddInitialisms("NPC", "", "npc")
+	if got := c.Initialisms(); !slices.Equal(got, []string{"npc", "ID"}) {
+		t.Errorf("Initialisms() = %q, want the last form of NPC and no empty entry", got)
+	}
+	c.AddInitialisms("NPC")
+	if got := c.ToUpperCamel("npc_id"); got != "NPCID" {
+		t.Errorf("ToUpperCamel after add = %q, want %q", got, "NPCID")
+	}
+	if got := c.ToUnderScore("NPCName"); got != "npc_name" {
+		t.Errorf("ToUnderScore after add = %q, want %q", got, "npc_name")
+	}
+
+	c.RemoveInitialisms("npc", "missing")
+	if got := c.ToUpperCamel("npc_id"); got != "NpcID" {
+		t.Errorf("ToUpperCamel after remove = %q, want %q", got, "NpcID")
+	}
+	if got := c.Initialisms(); !slices.Equal(got, []string{"ID"}) {
+		t.Errorf("Initialisms() = %q, want %q", got, []string{"ID"})
+	}
+
+	// the returned slice is a copy
+	c.Initialisms()[0] = "XX"
+	if got := c.Initialisms(); got[0] != "ID" {
+		t.Errorf("Initialisms() was modified through a returned slice: %q", got)
+	}
+}
+
+func TestConverterLongestInitialismFirst(t *testing.T) {
+	c := NewConverter("ID", "IDS")
+	if got := c.ToUpperCamel("ids_list"); got != "IDSList" {
+		t.Errorf("ToUpperCamel(%q) = %q, want %q", "ids_list", got, "IDSList")
+	}
+}
+
+func TestDefaultConverter(t *testing.T) {
+	if Default() != defaultConverter {
+		t.Fatal("Default() must return the converter of the package functions")
+	}
+	if got := ToUpperCamel("pvp_rank"); got != "PvpRank" {
+		t.Errorf("ToUpperCamel(%q) = %q, want %q", "pvp_rank", got, "PvpRank")
+	}
+
+	Default().AddInitialisms("PVP")
+	defer Default().RemoveInitialisms("PVP")
+	if got := ToUpperCamel("pvp_rank"); got != "PVPRank" {
+		t.Errorf("ToUpperCamel(%q) = %q, want %q", "pvp_rank", got, "PVPRank")
+	}
+}
+
+func TestConverterConcurrency(t *testing.T) {
+	c := NewConverter("ID")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				abbr := "X" + strconv.Itoa(i*100+j)
+				c.AddInitialisms(abbr)
+				c.RemoveInitialisms(abbr)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := c.ToUpperCamel("user_id"); got != "UserID" {
+					t.Errorf("ToUpperCamel(%q) = %q, want %q", "user_id", got, "UserID")
+				}
+				_ = c.ToUnderScore("UserID")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := c.Initialisms(); !slices.Equal(got, []string{"ID"}) {
+		t.Errorf("Initialisms() = %q, want %q", got, []string{"ID"})
+	}
+}
+
+func TestConverterConsistentSnapshot(t *testing.T) {
+	c := NewConverter("ID", "NPC")
+	input := strings.Repeat("NPCID_name_", 50)
+	// NPCIDName with NPC, NpcidName without it, never a mix such as NpcIDName
+	withNPC, withoutNPC := strings.Repeat("NPCIDName", 50), strings.Repeat("NpcidName", 50)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.RemoveInitialisms("NPC")
+				c.AddInitialisms("NPC")
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer close(stop)
+
+	for i := 0; i < 2000; i++ {
+		if got := c.ToUpperCamel(input); got != withNPC && got != withoutNPC {
+			t.Fatalf("ToUpperCamel mixed two initialism sets: %q", got)
+		}
+	}
+}
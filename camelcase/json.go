@@ -0,0 +1,178 @@
+package camelcase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// jsonFrame is an object or array being transformed
+type jsonFrame struct {
+	object    bool
+	count     int  // number of values written
+	expectKey bool // the next token of an object is a key
+}
+
+// jsonTransformer rewrites the keys of a JSON token stream
+type jsonTransformer struct {
+	converter *Converter
+	style     Style
+	w         *bufio.Writer
+	stack     []jsonFrame
+	values    int          // number of top level values written
+	quoteBuf  bytes.Buffer // output of quoter
+	quoter    *json.Encoder
+}
+
+// TransformJSONKeys copies the JSON values of src to dst with their object keys converted to style,
+// without decoding them into Go values. Leading and trailing runes other than letters and digits
+// of keys are kept ("_id", "$set"), and keys without any word ("_", "-") are kept as they are.
+// Other tokens keep their values, numbers included, but strings are decoded and encoded again:
+// their escapes may be written differently and invalid UTF-8 is replaced by U+FFFD.
+// The output is compact and top level values are separated by new lines.
+// Memory use is bounded by the nesting depth and the size of the largest token.
+// Keys which become equal after conversion are all kept.
+func (c *Converter) TransformJSONKeys(dst io.Writer, src io.Reader, style Style) error {
+	if style < Snake || style > UpperCamel {
+		return errors.Errorf("unknown case style %d", style)
+	}
+
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+
+	t := &jsonTransformer{
+		converter: c,
+		style:     style,
+		w:         bufio.NewWriter(dst),
+	}
+	t.quoter = json.NewEncoder(&t.quoteBuf)
+	t.quoter.SetEscapeHTML(false)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "read json token failed")
+		}
+		if err := t.write(tok); err != nil {
+			return err
+		}
+	}
+	if len(t.stack) > 0 {
+		return errors.Wrap(io.ErrUnexpectedEOF, "read json token failed")
+	}
+
+	return errors.Wrap(t.w.Flush(), "write json failed")
+}
+
+// write writes a token, with the separators before it
+func (t *jsonTransformer) write(tok json.Token) error {
+	var frame *jsonFrame
+	if len(t.stack) > 0 {
+		frame = &t.stack[len(t.stack)-1]
+	}
+
+	if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+		t.stack = t.stack[:len(t.stack)-1]
+		t.w.WriteByte(byte(delim))
+		t.endValue()
+		return nil
+	}
+
+	if frame != nil && frame.expectKey {
+		if frame.count > 0 {
+			t.w.WriteByte(',')
+		}
+		frame.expectKey = false
+		if err := t.writeString(t.convertKey(tok.(string))); err != nil {
+			return err
+		}
+		t.w.WriteByte(':')
+		return nil
+	}
+
+	switch {
+	case frame == nil && t.values > 0:
+		t.w.WriteByte('\n')
+	case frame != nil && !frame.object && frame.count > 0:
+		t.w.WriteByte(',')
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		t.w.WriteByte(byte(v))
+		t.stack = append(t.stack, jsonFrame{object: v == '{', expectKey: v == '{'})
+		return nil
+	case string:
+		if err := t.writeString(v); err != nil {
+			return err
+		}
+	case json.Number:
+		t.w.WriteString(v.String())
+	case bool:
+		if v {
+			t.w.WriteString("true")
+		} else {
+			t.w.WriteString("false")
+		}
+	case nil:
+		t.w.WriteString("null")
+	default:
+		return errors.Errorf("unexpected json token %T", tok)
+	}
+	t.endValue()
+	return nil
+}
+
+// convertKey converts the words of key to the style, keeping its leading and trailing non-word runes
+func (t *jsonTransformer) convertKey(key string) string {
+	isWord := func(r rune) bool { return runeClass(r) != runeSeparator }
+	start := strings.IndexFunc(key, isWord)
+	if start < 0 {
+		return key
+	}
+	end := strings.LastIndexFunc(key, isWord)
+	_, size := utf8.DecodeRuneInString(key[end:])
+	end += size
+
+	converted := t.converter.Convert(key[start:end], t.style)
+	if converted == "" {
+		return key
+	}
+	return key[:start] + converted + key[end:]
+}
+
+// endValue records the end of a value in its parent
+func (t *jsonTransformer) endValue() {
+	if len(t.stack) == 0 {
+		t.values++
+		return
+	}
+
+	frame := &t.stack[len(t.stack)-1]
+	frame.count++
+	frame.expectKey = frame.object
+}
+
+// writeString writes s as a JSON string, without escaping HTML characters
+func (t *jsonTransformer) writeString(s string) error {
+	t.quoteBuf.Reset()
+	if err := t.quoter.Encode(s); err != nil {
+		return errors.Wrap(err, "encode json string failed")
+	}
+	t.w.Write(bytes.TrimSuffix(t.quoteBuf.Bytes(), []byte{'\n'}))
+	return nil
+}
+
+// TransformJSONKeys copies the JSON values of src to dst with their object keys converted to style
+// by the default converter
+func TransformJSONKeys(dst io.Writer, src io.Reader, style Style) error {
+	return defaultConverter.TransformJSONKeys(dst, src, style)
+}
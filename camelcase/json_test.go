@@ -0,0 +1,139 @@
+package camelcase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTransformJSONKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		style Style
+		want  string
+	}{
+		{"Empty", "", LowerCamel, ""},
+		{"Scalar", ` "user_id" `, LowerCamel, `"user_id"`},
+		{"Object", `{"user_id": 1, "user_name": "first_name"}`, LowerCamel, `{"userID":1,"userName":"first_name"}`},
+		{"Back to snake", `{"userID":1,"userName":"firstName"}`, Snake, `{"user_id":1,"user_name":"firstName"}`},
+		{
+			"Nested",
+			`{"player_info": {"pvp_rank": [1, {"npc_list": []}, [{"item_id": null}]], "is_gm": true}, "empty_obj": {}}`,
+			UpperCamel,
+			`{"PlayerInfo":{"PvpRank":[1,{"NpcList":[]},[{"ItemID":null}]],"IsGm":true},"EmptyObj":{}}`,
+		},
+		{
+			"Values untouched",
+			`{"big_int": 12345678901234567890, "float": 1.50e+3, "html": "<a href=\"x\">&</a>", "unicode": "café ☕", "escaped": "a\nb\t\\"}`,
+			Kebab,
+			`{"big-int":12345678901234567890,"float":1.50e+3,"html":"<a href=\"x\">&</a>","unicode":"café ☕","escaped":"a\nb\t\\"}`,
+		},
+		{"Stream", "{\"a_b\":1}\n[{\"c_d\":2}] \"e_f\" 3", ScreamingSnake, "{\"A_B\":1}\n[{\"C_D\":2}]\n\"e_f\"\n3"},
+		{
+			"Non-word keys",
+			`{"_id":1,"$set":{"user_name":"x","$inc_by":2},"__v":2,"_":3,"-":4,"":5,"en-US":6,"user_id__":7,"¿qué_tal?":8}`,
+			LowerCamel,
+			`{"_id":1,"$set":{"userName":"x","$incBy":2},"__v":2,"_":3,"-":4,"":5,"enUs":6,"userID__":7,"¿quéTal?":8}`,
+		},
+		{"Escapes rewritten", `{"a_b":"caf\u00e9 \/ \u003c"}`, LowerCamel, `{"aB":"café / <"}`},
+		{"Array of arrays", `[[1,2],[],[[3]]]`, Snake, `[[1,2],[],[[3]]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := TransformJSONKeys(&buf, strings.NewReader(tt.input), tt.style); err != nil {
+				t.Fatalf("TransformJSONKeys(%q) failed: %v", tt.input, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("TransformJSONKeys(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformJSONKeysErrors(t *testing.T) {
+	inputs := []string{`{"a": }`, `{"a": 1`, `[1, 2`, `{"a" 1}`, `}`}
+	for _, input := range inputs {
+		if err := TransformJSONKeys(io.Discard, strings.NewReader(input), Snake); err == nil {
+			t.Errorf("TransformJSONKeys(%q) must fail", input)
+		}
+	}
+
+	if err := TransformJSONKeys(io.Discard, strings.NewReader(`{}`), Style(-1)); err == nil {
+		t.Error("TransformJSONKeys with an unknown style must fail")
+	}
+}
+
+func TestTransformJSONKeysConverter(t *testing.T) {
+	c := NewConverter("NPC")
+	var buf bytes.Buffer
+	if err := c.TransformJSONKeys(&buf, strings.NewReader(`{"npc_id": "npc_id"}`), UpperCamel); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `{"NPCId":"npc_id"}`; got != want {
+		t.Errorf("TransformJSONKeys = %q, want %q", got, want)
+	}
+}
+
+// jsonArrayReader generates an array of n objects without holding it in memory
+type jsonArrayReader struct {
+	n, i int
+	buf  []byte
+}
+
+func (r *jsonArrayReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		switch {
+		case r.i > r.n:
+			return 0, io.EOF
+		case r.i == r.n:
+			r.buf = []byte("]")
+		case r.i == 0:
+			r.buf = []byte(`[{"item_id":0}`)
+		default:
+			r.buf = []byte(fmt.Sprintf(`,{"item_id":%d}`, r.i))
+		}
+		r.i++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// countingWriter counts the converted objects written, across writes
+type countingWriter struct {
+	objects int
+	tail    []byte
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	pattern := []byte(`{"itemID":`)
+	data := append(w.tail, p...)
+	w.objects += bytes.Count(data, pattern)
+	w.tail = append([]byte(nil), data[max(0, len(data)-len(pattern)+1):]...)
+	return len(p), nil
+}
+
+func TestTransformJSONKeysLargeStream(t *testing.T) {
+	const n = 100000
+	w := &countingWriter{}
+	if err := TransformJSONKeys(w, &jsonArrayReader{n: n}, LowerCamel); err != nil {
+		t.Fatal(err)
+	}
+	if w.objects != n {
+		t.Errorf("TransformJSONKeys wrote %d objects, want %d", w.objects, n)
+	}
+}
+
+func BenchmarkTransformJSONKeys(b *testing.B) {
+	input := []byte(`{"player_info":{"user_id":1,"user_name":"name","item_list":[{"item_id":1,"item_count":2}]}}`)
+	for i := 0; i < b.N; i++ {
+		if err := TransformJSONKeys(io.Discard, bytes.NewReader(input), LowerCamel); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package camelcase
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Style is a case style of identifiers
+type Style int
+
+const (
+	Snake          Style = iota // snake_case
+	ScreamingSnake              // SCREAMING_SNAKE_CASE
+	Kebab                       // kebab-case
+	Dot                         // dot.case
+	Title                       // Title Case
+	LowerCamel                  // lowerCamelCase
+	UpperCamel                  // UpperCamelCase
+)
+
+var styleNames = [...]string{
+	Snake:          "snake",
+	ScreamingSnake: "screaming_snake",
+	Kebab:          "kebab",
+	Dot:            "dot",
+	Title:          "title",
+	LowerCamel:     "lower_camel",
+	UpperCamel:     "upper_camel",
+}
+
+// String returns the name of the style in snake case, such as "lower_camel"
+func (s Style) String() string {
+	if s < 0 || int(s) >= len(styleNames) {
+		return "Style(" + strconv.Itoa(int(s)) + ")"
+	}
+	return styleNames[s]
+}
+
+// ParseStyle returns the style named name, written in any case style ("lower_camel", "lowerCamel", "LOWER-CAMEL"...)
+func ParseStyle(name string) (Style, error) {
+	normalized := Convert(name, Snake)
+	for style, styleName := range styleNames {
+		if styleName == normalized {
+			return Style(style), nil
+		}
+	}
+	return 0, errors.Errorf("unknown case style %q", name)
+}
+
+// Convert converts s to style. Converting the result to another style and back gives it unchanged,
+// unless camel case styles join words of s into one: single letters ("a_b" is "AB"), consecutive numbers
+// ("x86_64" is "X8664") and words spelling an initialism ("my_sql" is "MySql", read as MySQL).
+func (c *Converter) Convert(s string, style Style) string {
+	// a single snapshot of the initialisms, which may be updated during the conversion
+	state := c.state.Load()
+	words := state.words(s)
+	if len(words) == 0 {
+		return ""
+	}
+
+	switch style {
+	case Snake:
+		return joinWords(words, "_", strings.ToLower)
+	case ScreamingSnake:
+		return joinWords(words, "_", strings.ToUpper)
+	case Kebab:
+		return joinWords(words, "-", strings.ToLower)
+	case Dot:
+		return joinWords(words, ".", strings.ToLower)
+	case Title:
+		return joinWords(words, " ", state.title)
+	case LowerCamel:
+		first := strings.ToLower(words[0])
+		return first + joinWords(words[1:], "", state.title)
+	case UpperCamel:
+		return joinWords(words, "", state.title)
+	default:
+		panic("unknown case style")
+	}
+}
+
+// title returns word with an upper case first letter, or its canonical form if it is an initialism
+// or the plural of one ("IDs")
+func (s *converterState) title(word string) string {
+	lower := strings.ToLower(word)
+	if canonical, ok := s.canonical[lower]; ok {
+		return canonical
+	}
+	if stem, ok := strings.CutSuffix(lower, "s"); ok {
+		if canonical, ok := s.canonical[stem]; ok {
+			return canonical + "s"
+		}
+	}
+
+	r := []rune(strings.ToLower(word))
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func joinWords(words []string, sep string, transform func(string) string) string {
+	var builder strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			builder.WriteString(sep)
+		}
+		builder.WriteString(transform(word))
+	}
+	return builder.String()
+}
+
+// Convert converts s to style with the default converter
+func Convert(s string, style Style) string {
+	return defaultConverter.Convert(s, style)
+}
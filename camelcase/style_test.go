@@ -0,0 +1,127 @@
+package camelcase
+
+import (
+	"testing"
+)
+
+var allStyles = []Style{Snake, ScreamingSnake, Kebab, Dot, Title, LowerCamel, UpperCamel}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		input string
+		want  map[Style]string
+	}{
+		{"HTTPServerID", map[Style]string{
+			Snake:          "http_server_id",
+			ScreamingSnake: "HTTP_SERVER_ID",
+			Kebab:          "http-server-id",
+			Dot:            "http.server.id",
+			Title:          "HTTP Server ID",
+			LowerCamel:     "httpServerID",
+			UpperCamel:     "HTTPServerID",
+		}},
+		{"player2FA", map[Style]string{
+			Snake:          "player_2_fa",
+			ScreamingSnake: "PLAYER_2_FA",
+			Kebab:          "player-2-fa",
+			Dot:            "player.2.fa",
+			Title:          "Player 2 Fa",
+			LowerCamel:     "player2Fa",
+			UpperCamel:     "Player2Fa",
+		}},
+		{"my sql.query-url", map[Style]string{
+			Snake:          "my_sql_query_url",
+			ScreamingSnake: "MY_SQL_QUERY_URL",
+			Kebab:          "my-sql-query-url",
+			Dot:            "my.sql.query.url",
+			Title:          "My Sql Query URL",
+			LowerCamel:     "mySqlQueryURL",
+			UpperCamel:     "MySqlQueryURL",
+		}},
+		{"こんにちは_世界", map[Style]string{
+			Snake:      "こんにちは_世界",
+			Title:      "こんにちは 世界",
+			UpperCamel: "こんにちは世界",
+		}},
+		{"__", map[Style]string{
+			Snake:      "",
+			UpperCamel: "",
+		}},
+	}
+
+	for _, tt := range tests {
+		for style, want := range tt.want {
+			if got := Convert(tt.input, style); got != want {
+				t.Errorf("Convert(%q, %d) = %q, want %q", tt.input, style, got, want)
+			}
+		}
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	inputs := []string{
+		"HTTPServerID", "player2FA", "player_2fa", "UserID2", "MySQLQuery", "user_name",
+		"XMLHttpRequest", "Vector3Add", "PlayerIDs", "URLs", "NPCs", "item sku id", "json.api.version", "こんにちはWorld",
+		"item_2_id", "api_v2_url", "version2Api",
+	}
+
+	for _, input := range inputs {
+		for _, from := range allStyles {
+			name := Convert(input, from)
+			for _, to := range allStyles {
+				if got := Convert(Convert(name, to), from); got != name {
+					t.Errorf("Convert(Convert(%q, %d), %d) = %q, want %q", name, to, from, got, name)
+				}
+			}
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for _, style := range allStyles {
+		got, err := ParseStyle(style.String())
+		if err != nil || got != style {
+			t.Errorf("ParseStyle(%q) = %v, %v, want %v", style.String(), got, err, style)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  Style
+	}{
+		{"lowerCamel", LowerCamel},
+		{"UpperCamel", UpperCamel},
+		{"SCREAMING-SNAKE", ScreamingSnake},
+		{"Title", Title},
+	}
+	for _, tt := range tests {
+		if got, err := ParseStyle(tt.input); err != nil || got != tt.want {
+			t.Errorf("ParseStyle(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "camel", "snake case"} {
+		if _, err := ParseStyle(input); err == nil {
+			t.Errorf("ParseStyle(%q) must fail", input)
+		}
+	}
+	if got := Style(100).String(); got != "Style(100)" {
+		t.Errorf("Style(100).String() = %q, want %q", got, "Style(100)")
+	}
+}
+
+func TestConvertUnknownStyle(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Convert with an unknown style must panic")
+		}
+	}()
+	Convert("hello", Style(100))
+}
+
+func BenchmarkConvert(b *testing.B) {
+	testString := "HTTPServerIDForPlayer2FA"
+	for i := 0; i < b.N; i++ {
+		Convert(testString, Kebab)
+	}
+}
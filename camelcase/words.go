@@ -0,0 +1,171 @@
+package camelcase
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rune classes used to find word boundaries
+const (
+	runeSeparator = iota
+	runeLower     // lower case and caseless letters
+	runeUpper
+	runeDigit
+)
+
+// token is a word of an identifier
+type token struct {
+	text   string
+	joined bool // the token follows the previous one without a separator, as "SQL" in "MySQL"
+}
+
+// tokenize splits s into words. Words are separated by any rune other than a letter or a digit,
+// by a change to upper case ("userID"), by the last upper case letter of an acronym run
+// followed by a lower case letter ("HTTPServer"), and by the start and the end of a number ("user2", "2FA").
+// A lone "s" after an acronym run is its plural ("userIDs", "URLs").
+func tokenize(s string) []token {
+	runes := []rune(s)
+	tokens := make([]token, 0, 4)
+
+	start := -1
+	joined := false
+	for i, r := range runes {
+		c := runeClass(r)
+		if c == runeSeparator {
+			if start >= 0 {
+				tokens = append(tokens, token{text: string(runes[start:i]), joined: joined})
+				start = -1
+			}
+			joined = false
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runeClass(runes[i-1])
+		next := runeSeparator
+		if i+1 < len(runes) {
+			next = runeClass(runes[i+1])
+		}
+
+		if (c == runeDigit) != (prev == runeDigit) ||
+			(c == runeUpper && prev == runeLower) ||
+			(c == runeUpper && prev == runeUpper && next == runeLower && !isPlural(runes, i+1)) {
+			tokens = append(tokens, token{text: string(runes[start:i]), joined: joined})
+			start = i
+			joined = true
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: string(runes[start:]), joined: joined})
+	}
+
+	return tokens
+}
+
+// isPlural checks if runes[i] is a lone "s" ending a word
+func isPlural(runes []rune, i int) bool {
+	return i < len(runes) && runes[i] == 's' && (i+1 == len(runes) || runeClass(runes[i+1]) != runeLower)
+}
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsUpper(r) || unicode.IsTitle(r):
+		return runeUpper
+	case unicode.IsLetter(r) || unicode.IsMark(r):
+		return runeLower
+	case unicode.IsDigit(r):
+		return runeDigit
+	default:
+		return runeSeparator
+	}
+}
+
+// Words splits s into words, keeping the initialisms of the converter in one word:
+// "MySQLQuery" gives "My", "SQL" joined as "MySQL" and "Query", "NPCID" gives "NPC" and "ID" if both are initialisms.
+func (c *Converter) Words(s string) []string {
+	return c.state.Load().words(s)
+}
+
+// words splits str into words with the initialisms of the state
+func (s *converterState) words(str string) []string {
+	tokens := tokenize(str)
+	words := make([]string, 0, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+		// join the longest run of tokens written as a known initialism
+		end, abbr := i, ""
+		var joined strings.Builder
+		joined.WriteString(tokens[i].text)
+		for j := i + 1; j < len(tokens) && tokens[j].joined; j++ {
+			joined.WriteString(tokens[j].text)
+			if joined.Len() > s.maxLen {
+				break
+			}
+			if canonical, ok := s.canonical[strings.ToLower(joined.String())]; ok {
+				end, abbr = j, canonical
+			}
+		}
+		if end > i {
+			words = append(words, abbr)
+			i = end
+			continue
+		}
+
+		var next rune
+		if i+1 < len(tokens) && tokens[i+1].joined {
+			next, _ = utf8.DecodeRuneInString(tokens[i+1].text)
+		}
+		words = append(words, s.splitInitialisms(tokens[i].text, runeClass(next) == runeDigit)...)
+	}
+
+	return words
+}
+
+// splitInitialisms splits an upper case word made of several initialisms, such as "NPCID",
+// the plural "s" of "NPCIDs" staying on the last one. Before a number, the last letter can also be
+// a word of its own, as the version letter of "APIV2".
+func (s *converterState) splitInitialisms(word string, beforeNumber bool) []string {
+	stem, plural := word, ""
+	if strings.HasSuffix(word, "s") {
+		stem, plural = word[:len(word)-1], "s"
+	}
+	if _, ok := s.canonical[strings.ToLower(word)]; ok || stem == "" || strings.ToUpper(stem) != stem {
+		return []string{word}
+	}
+	if parts, ok := s.segment(strings.ToLower(stem)); ok {
+		parts[len(parts)-1] += plural
+		return parts
+	}
+	if _, size := utf8.DecodeLastRuneInString(word); beforeNumber && plural == "" && size < len(word) {
+		if parts, ok := s.segment(strings.ToLower(word[:len(word)-size])); ok {
+			return append(parts, word[len(word)-size:])
+		}
+	}
+	return []string{word}
+}
+
+// segment splits lower into initialisms, preferring the longest first
+func (s *converterState) segment(lower string) ([]string, bool) {
+	if lower == "" {
+		return nil, true
+	}
+	for n := min(len(lower), s.maxLen); n > 0; n-- {
+		canonical, ok := s.canonical[lower[:n]]
+		if !ok {
+			continue
+		}
+		if rest, ok := s.segment(lower[n:]); ok {
+			return append([]string{canonical}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+// Words splits s into words with the default converter
+func Words(s string) []string {
+	return defaultConverter.Words(s)
+}
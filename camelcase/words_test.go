@@ -0,0 +1,85 @@
+package camelcase
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"hello", []string{"hello"}},
+		{"helloWorld", []string{"hello", "World"}},
+		{"HTTPServerID", []string{"HTTP", "Server", "ID"}},
+		{"player2FA", []string{"player", "2", "FA"}},
+		{"PLAYER_2FA_TOKEN", []string{"PLAYER", "2", "FA", "TOKEN"}},
+		{"2FAToken", []string{"2", "FA", "Token"}},
+		{"player_2fa", []string{"player", "2", "fa"}},
+		{"item2IDs", []string{"item", "2", "IDs"}},
+		{"UserID2", []string{"User", "ID", "2"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"URLs", []string{"URLs"}},
+		{"NPCs", []string{"NPCs"}},
+		{"PlayerIDsList", []string{"Player", "IDs", "List"}},
+		{"HTTPSession", []string{"HTTP", "Session"}},
+		{"userIsActive", []string{"user", "Is", "Active"}},
+		{"Vector3Add", []string{"Vector", "3", "Add"}},
+		{"hello world.foo-bar__baz", []string{"hello", "world", "foo", "bar", "baz"}},
+		{"  __leading and trailing--  ", []string{"leading", "and", "trailing"}},
+		{"HELLO_WORLD", []string{"HELLO", "WORLD"}},
+		{"こんにちはWorld", []string{"こんにちは", "World"}},
+		{"ÉcoleNormale", []string{"École", "Normale"}},
+		{"straßeNummer", []string{"straße", "Nummer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens := tokenize(tt.input)
+			var got []string
+			for _, token := range tokens {
+				got = append(got, token.text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	c := NewConverter("MySQL", "NPC", "ID", "UTF8", "IDS")
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"MySQLQuery", []string{"MySQL", "Query"}},
+		{"mysql_query", []string{"mysql", "query"}},
+		{"my_sql", []string{"my", "sql"}},
+		{"NPCID", []string{"NPC", "ID"}},
+		{"NPCIDS", []string{"NPC", "IDS"}},
+		{"NPCX", []string{"NPCX"}},
+		{"NPCIDs", []string{"NPC", "IDs"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"NPCs", []string{"NPCs"}},
+		{"IDS", []string{"IDS"}},
+		{"utf8String", []string{"UTF8", "String"}},
+		{"UTF8_ID", []string{"UTF8", "ID"}},
+		{"item2ID", []string{"item", "2", "ID"}},
+		{"NPCV2", []string{"NPC", "V", "2"}},
+		{"NPCV_2", []string{"NPCV", "2"}},
+		{"SHA256", []string{"SHA", "256"}},
+		{"npcId", []string{"npc", "Id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := c.Words(tt.input); !slices.Equal(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Command tagcase adds and normalizes the struct tags of Go source files with the camelcase converters.
+//
+// Usage:
+//
+//	tagcase [flags] [path ...]
+//
+// Paths are files or directories walked recursively, skipping vendor, testdata, hidden directories and
+// generated files. Every exported field gets the tags listed by -tags, named after the field in the case
+// style of -style unless the tag sets its own style ("json,bson=snake"). Existing tag names are converted
+// to the style, keeping their options. With -check, files are not written and violations are reported,
+// exiting with status 1 if any.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vulcan-frame/vulcan-pkg-tool/camelcase"
+)
+
+var (
+	tagsFlag        = flag.String("tags", "json", "comma separated tag keys, each optionally with its own style as key=style")
+	styleFlag       = flag.String("style", "lower_camel", "default case style: snake, screaming_snake, kebab, dot, title, lower_camel or upper_camel")
+	initialismsFlag = flag.String("initialisms", "", "comma separated initialisms added to the default ones, such as NPC,PVP")
+	addFlag         = flag.Bool("add", true, "add the missing tags to exported fields")
+	checkFlag       = flag.Bool("check", false, "report violations without writing files")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: tagcase [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	r, err := newRewriter(*tagsFlag, *styleFlag, *initialismsFlag, *addFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tagcase:", err)
+		os.Exit(2)
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	violations := 0
+	for _, path := range paths {
+		err := walk(path, func(filename string) error {
+			n, err := r.processFile(filename, *checkFlag)
+			violations += n
+			return err
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "tagcase:", err)
+			os.Exit(2)
+		}
+	}
+
+	if *checkFlag && violations > 0 {
+		os.Exit(1)
+	}
+}
+
+// newRewriter creates a rewriter from the command line flags
+func newRewriter(tags, style, initialisms string, add bool) (*rewriter, error) {
+	defaultStyle, err := camelcase.ParseStyle(style)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rewriter{
+		add:       add,
+		converter: camelcase.NewConverter(camelcase.Default().Initialisms()...),
+	}
+	if initialisms != "" {
+		r.converter.AddInitialisms(strings.Split(initialisms, ",")...)
+	}
+
+	for _, tag := range strings.Split(tags, ",") {
+		key, name, ok := strings.Cut(strings.TrimSpace(tag), "=")
+		if key == "" {
+			return nil, errors.Errorf("empty tag key in %q", tags)
+		}
+		rule := rule{key: key, style: defaultStyle}
+		if ok {
+			if rule.style, err = camelcase.ParseStyle(name); err != nil {
+				return nil, err
+			}
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return r, nil
+}
+
+// walk calls fn for the Go files of path
+func walk(path string, fn func(filename string) error) error {
+	return filepath.WalkDir(path, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if filename != path && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filename != path && !strings.HasSuffix(filename, ".go") {
+			return nil
+		}
+		return fn(filename)
+	})
+}
+
+// processFile normalizes the struct tags of a file, writing it back unless check is set.
+// It returns the number of violations found.
+func (r *rewriter) processFile(filename string, check bool) (int, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return 0, err
+	}
+	if ast.IsGenerated(file) {
+		return 0, nil
+	}
+
+	violations, err := r.rewrite(fset, file)
+	if err != nil {
+		return 0, err
+	}
+	if check {
+		for _, v := range violations {
+			fmt.Println(v)
+		}
+		return len(violations), nil
+	}
+	if len(violations) == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return 0, errors.Wrapf(err, "format %s", filename)
+	}
+	if bytes.Equal(buf.Bytes(), src) {
+		return 0, nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	fmt.Println(filename)
+	return len(violations), nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcan-frame/vulcan-pkg-tool/camelcase"
+)
+
+func TestNewRewriter(t *testing.T) {
+	r, err := newRewriter("json, bson=snake,yaml=kebab", "lowerCamel", "NPC,PVP", false)
+	require.NoError(t, err)
+	assert.Equal(t, []rule{
+		{key: "json", style: camelcase.LowerCamel},
+		{key: "bson", style: camelcase.Snake},
+		{key: "yaml", style: camelcase.Kebab},
+	}, r.rules)
+	assert.False(t, r.add)
+	assert.Equal(t, "npcPVP", r.converter.ToLowerCamel("NPC_PVP"))
+	assert.Equal(t, "NpcPvp", camelcase.ToUpperCamel("npc_pvp"), "the default converter must not be modified")
+
+	_, err = newRewriter("json", "camel", "", true)
+	assert.Error(t, err)
+	_, err = newRewriter("json=unknown", "snake", "", true)
+	assert.Error(t, err)
+	_, err = newRewriter("json,,bson", "snake", "", true)
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.go", "b.txt", "sub/c.go", "vendor/d.go", "testdata/e.go", ".git/f.go", "sub/.hidden/g.go",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	var got []string
+	err := walk(dir, func(filename string) error {
+		rel, err := filepath.Rel(dir, filename)
+		got = append(got, filepath.ToSlash(rel))
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "sub/c.go"}, got)
+
+	// a file given explicitly is processed whatever its name
+	got = nil
+	require.NoError(t, walk(filepath.Join(dir, "b.txt"), func(filename string) error {
+		got = append(got, filepath.Base(filename))
+		return nil
+	}))
+	assert.Equal(t, []string{"b.txt"}, got)
+
+	assert.Error(t, walk(filepath.Join(dir, "missing"), func(string) error { return nil }))
+}
+
+func TestProcessFile(t *testing.T) {
+	r, err := newRewriter("json", "snake", "", true)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "doc.go")
+	src := "package doc\n\ntype Player struct {\n\tUserID string\n}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o600))
+
+	n, err := r.processFile(filename, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, src, string(data), "check mode must not write the file")
+
+	n, err = r.processFile(filename, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	data, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "package doc\n\ntype Player struct {\n\tUserID string `json:\"user_id\"`\n}\n", string(data))
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	n, err = r.processFile(filename, true)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	t.Run("generated", func(t *testing.T) {
+		generated := filepath.Join(dir, "generated.go")
+		src := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage doc\n\ntype T struct {\n\tName string\n}\n"
+		require.NoError(t, os.WriteFile(generated, []byte(src), 0o644))
+		n, err := r.processFile(generated, false)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.go")
+		require.NoError(t, os.WriteFile(invalid, []byte("package"), 0o644))
+		_, err := r.processFile(invalid, true)
+		assert.Error(t, err)
+		_, err = r.processFile(filepath.Join(dir, "missing.go"), true)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vulcan-frame/vulcan-pkg-tool/camelcase"
+)
+
+// rule sets the case style of the names of a tag key
+type rule struct {
+	key   string
+	style camelcase.Style
+}
+
+// rewriter normalizes the struct tags of parsed files
+type rewriter struct {
+	rules     []rule
+	add       bool // add the tags missing on exported fields
+	converter *camelcase.Converter
+}
+
+// violation is a struct tag not following the rules
+type violation struct {
+	pos  token.Position
+	text string
+}
+
+func (v violation) String() string {
+	return fmt.Sprintf("%s: %s", v.pos, v.text)
+}
+
+// rewrite normalizes the struct tags of file in place and returns the violations found
+func (r *rewriter) rewrite(fset *token.FileSet, file *ast.File) ([]violation, error) {
+	var violations []violation
+	var err error
+	ast.Inspect(file, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok || err != nil {
+			return err == nil
+		}
+		for _, field := range st.Fields.List {
+			var fieldViolations []violation
+			if fieldViolations, err = r.rewriteField(fset, field); err != nil {
+				return false
+			}
+			violations = append(violations, fieldViolations...)
+		}
+		return true
+	})
+	return violations, err
+}
+
+// rewriteField normalizes the tag of field, skipping embedded, unexported and multiple name fields
+func (r *rewriter) rewriteField(fset *token.FileSet, field *ast.Field) ([]violation, error) {
+	if len(field.Names) != 1 || !field.Names[0].IsExported() {
+		return nil, nil
+	}
+	name := field.Names[0].Name
+
+	var tag structTag
+	if field.Tag != nil {
+		value, err := strconv.Unquote(field.Tag.Value)
+		if err == nil {
+			tag, err = parseTag(value)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: field %s", fset.Position(field.Tag.Pos()), name)
+		}
+	}
+
+	var violations []violation
+	changed := false
+	for _, rule := range r.rules {
+		value, ok := tag.Get(rule.key)
+		if !ok && !r.add {
+			continue
+		}
+
+		want, skip := r.normalize(name, value, ok, rule.style)
+		if skip || (ok && value == want) {
+			continue
+		}
+
+		pos := field.Names[0].Pos()
+		if field.Tag != nil {
+			pos = field.Tag.Pos()
+		}
+		text := fmt.Sprintf("field %s: missing %s tag %q", name, rule.key, want)
+		if ok {
+			text = fmt.Sprintf("field %s: %s tag %q should be %q", name, rule.key, value, want)
+		}
+		violations = append(violations, violation{pos: fset.Position(pos), text: text})
+
+		tag = tag.Set(rule.key, want)
+		changed = true
+	}
+
+	if changed {
+		value := tag.String()
+		if strings.Contains(value, "`") {
+			value = strconv.Quote(value)
+		} else {
+			value = "`" + value + "`"
+		}
+		if field.Tag == nil {
+			field.Tag = &ast.BasicLit{ValuePos: field.Type.End(), Kind: token.STRING}
+		}
+		field.Tag.Value = value
+	}
+	return violations, nil
+}
+
+// normalize returns the wanted value of a tag of the field named name. The name of an existing value
+// is converted to style, keeping its options and leading underscores such as the "_id" of bson, names without
+// words such as "-," are kept, and the name of a missing value is the field name. Ignored ("-") and inline fields are skipped.
+func (r *rewriter) normalize(name, value string, exists bool, style camelcase.Style) (string, bool) {
+	if !exists {
+		return r.converter.Convert(name, style), false
+	}
+
+	tagName, options, hasOptions := strings.Cut(value, ",")
+	if tagName == "-" && !hasOptions {
+		return "", true
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "inline" {
+			return "", true
+		}
+	}
+
+	if tagName == "" {
+		tagName = name
+	}
+	trimmed := strings.TrimLeft(tagName, "_")
+	if converted := r.converter.Convert(trimmed, style); converted != "" {
+		tagName = tagName[:len(tagName)-len(trimmed)] + converted
+	}
+
+	if hasOptions {
+		return tagName + "," + options, false
+	}
+	return tagName, false
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcan-frame/vulcan-pkg-tool/camelcase"
+)
+
+const rewriteSource = `package doc
+
+type Player struct {
+	ID       string ` + "`bson:\"_id\" json:\"id\"`" + `
+	UserName string ` + "`json:\"user_name,omitempty\"`" + ` // login name
+	NPCLevel int
+	Internal string ` + "`json:\"-\"`" + `
+	Extra    map[string]any ` + "`bson:\",inline\" json:\",inline\"`" + `
+	Quoted   string ` + "\"json:\\\"QUOTED\\\"\"" + `
+	A, B     int
+	hidden   int
+	embedded
+	Nested   struct {
+		HTTPPort int ` + "`json:\"HTTPPort\" yaml:\"x\"`" + `
+	}
+}
+`
+
+func rewriteString(t *testing.T, r *rewriter, src string) (string, []string) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "doc.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	violations, err := r.rewrite(fset, file)
+	require.NoError(t, err)
+	texts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		texts = append(texts, v.String())
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, format.Node(&buf, fset, file))
+	return buf.String(), texts
+}
+
+func TestRewrite(t *testing.T) {
+	converter := camelcase.NewConverter("ID", "HTTP", "NPC")
+	r := &rewriter{
+		rules: []rule{
+			{key: "json", style: camelcase.LowerCamel},
+			{key: "bson", style: camelcase.Snake},
+		},
+		add:       true,
+		converter: converter,
+	}
+
+	got, violations := rewriteString(t, r, rewriteSource)
+	assert.Equal(t, `package doc
+
+type Player struct {
+	ID       string         `+"`bson:\"_id\" json:\"id\"`"+`
+	UserName string         `+"`json:\"userName,omitempty\" bson:\"user_name\"`"+` // login name
+	NPCLevel int            `+"`json:\"npcLevel\" bson:\"npc_level\"`"+`
+	Internal string         `+"`json:\"-\" bson:\"internal\"`"+`
+	Extra    map[string]any `+"`bson:\",inline\" json:\",inline\"`"+`
+	Quoted   string         `+"`json:\"quoted\" bson:\"quoted\"`"+`
+	A, B     int
+	hidden   int
+	embedded
+	Nested struct {
+		HTTPPort int `+"`json:\"httpPort\" yaml:\"x\" bson:\"http_port\"`"+`
+	} `+"`json:\"nested\" bson:\"nested\"`"+`
+}
+`, got)
+	assert.Equal(t, []string{
+		`doc.go:5:18: field UserName: json tag "user_name,omitempty" should be "userName,omitempty"`,
+		`doc.go:5:18: field UserName: missing bson tag "user_name"`,
+		`doc.go:6:2: field NPCLevel: missing json tag "npcLevel"`,
+		`doc.go:6:2: field NPCLevel: missing bson tag "npc_level"`,
+		`doc.go:7:18: field Internal: missing bson tag "internal"`,
+		`doc.go:9:18: field Quoted: json tag "QUOTED" should be "quoted"`,
+		`doc.go:9:18: field Quoted: missing bson tag "quoted"`,
+		`doc.go:13:2: field Nested: missing json tag "nested"`,
+		`doc.go:13:2: field Nested: missing bson tag "nested"`,
+		`doc.go:14:16: field HTTPPort: json tag "HTTPPort" should be "httpPort"`,
+		`doc.go:14:16: field HTTPPort: missing bson tag "http_port"`,
+	}, violations)
+
+	again, violations := rewriteString(t, r, got)
+	assert.Equal(t, got, again)
+	assert.Empty(t, violations)
+
+	t.Run("no add", func(t *testing.T) {
+		r := &rewriter{rules: []rule{{key: "json", style: camelcase.Snake}}, converter: converter}
+		got, violations := rewriteString(t, r, rewriteSource)
+		assert.Contains(t, got, "NPCLevel int\n")
+		assert.Contains(t, got, "`json:\"user_name,omitempty\"`")
+		assert.Contains(t, got, "`json:\"http_port\" yaml:\"x\"`")
+		assert.Len(t, violations, 2)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "doc.go", "package doc\n\ntype T struct {\n\tName string `json:name`\n}\n", 0)
+		require.NoError(t, err)
+		_, err = r.rewrite(fset, file)
+		assert.ErrorContains(t, err, "doc.go:4:14: field Name")
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	r := &rewriter{converter: camelcase.NewConverter("ID")}
+
+	tests := []struct {
+		name   string
+		value  string
+		exists bool
+		style  camelcase.Style
+		want   string
+		skip   bool
+	}{
+		{"UserID", "", false, camelcase.Snake, "user_id", false},
+		{"PlayerIDs", "", false, camelcase.Snake, "player_ids", false},
+		{"PlayerIDs", "", false, camelcase.LowerCamel, "playerIDs", false},
+		{"UserID", "userId", true, camelcase.Kebab, "user-id", false},
+		{"UserID", ",omitempty", true, camelcase.LowerCamel, "userID,omitempty", false},
+		{"UserID", "user,", true, camelcase.UpperCamel, "User,", false},
+		{"ID", "_id", true, camelcase.ScreamingSnake, "_ID", false},
+		{"ID", "__meta_data", true, camelcase.Dot, "__meta.data", false},
+		{"UserID", "-", true, camelcase.Snake, "", true},
+		{"UserID", "-,", true, camelcase.Snake, "-,", false},
+		{"Extra", "extra,inline", true, camelcase.Snake, "", true},
+	}
+
+	for _, tt := range tests {
+		got, skip := r.normalize(tt.name, tt.value, tt.exists, tt.style)
+		assert.Equal(t, tt.skip, skip, tt.value)
+		if !tt.skip {
+			assert.Equal(t, tt.want, got, tt.value)
+		}
+	}
+}
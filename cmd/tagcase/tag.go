@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tagPair is a key:"value" pair of a struct tag
+type tagPair struct {
+	key   string
+	value string
+}
+
+// structTag is a parsed struct tag, keeping the order of its keys
+type structTag []tagPair
+
+// parseTag parses a struct tag following the conventions of reflect.StructTag
+func parseTag(tag string) (structTag, error) {
+	var pairs structTag
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return pairs, nil
+		}
+
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, errors.Errorf("malformed struct tag %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, errors.Errorf("unterminated value of struct tag key %q", key)
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of struct tag key %q", key)
+		}
+		tag = tag[i+1:]
+
+		pairs = append(pairs, tagPair{key: key, value: value})
+	}
+}
+
+// Get returns the value of key
+func (t structTag) Get(key string) (string, bool) {
+	for _, pair := range t {
+		if pair.key == key {
+			return pair.value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value of key, appending key if it is missing
+func (t structTag) Set(key, value string) structTag {
+	for i := range t {
+		if t[i].key == key {
+			t[i].value = value
+			return t
+		}
+	}
+	return append(t, tagPair{key: key, value: value})
+}
+
+// String returns the tag in its canonical form
+func (t structTag) String() string {
+	var builder strings.Builder
+	for i, pair := range t {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(pair.key)
+		builder.WriteByte(':')
+		builder.WriteString(strconv.Quote(pair.value))
+	}
+	return builder.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	tag, err := parseTag(`json:"name,omitempty"  bson:"_id" validate:"a \"quoted\" value"`)
+	require.NoError(t, err)
+	assert.Equal(t, structTag{
+		{key: "json", value: "name,omitempty"},
+		{key: "bson", value: "_id"},
+		{key: "validate", value: `a "quoted" value`},
+	}, tag)
+	assert.Equal(t, `json:"name,omitempty" bson:"_id" validate:"a \"quoted\" value"`, tag.String())
+
+	value, ok := tag.Get("bson")
+	assert.True(t, ok)
+	assert.Equal(t, "_id", value)
+	_, ok = tag.Get("yaml")
+	assert.False(t, ok)
+
+	tag = tag.Set("bson", "id").Set("yaml", "name")
+	assert.Equal(t, `json:"name,omitempty" bson:"id" validate:"a \"quoted\" value" yaml:"name"`, tag.String())
+
+	empty, err := parseTag("  ")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	for _, malformed := range []string{`json`, `json:name`, `:"name"`, `json:"name`, `json:"\q"`} {
+		_, err := parseTag(malformed)
+		assert.Error(t, err, malformed)
+	}
+}
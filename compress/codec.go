@@ -0,0 +1,267 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// CodecID identifies a codec in the header of encoded data, from 0 to MaxCodecID
+type CodecID uint8
+
+const (
+	None CodecID = iota // stored without compression
+	Zlib
+	Gzip
+	Flate
+	Snappy
+	Zstd
+)
+
+const MaxCodecID CodecID = 15
+
+// Level selects the trade-off between speed and compression ratio
+type Level int
+
+const (
+	LevelFast Level = iota
+	LevelDefault
+)
+
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec compresses and decompresses data in one format
+type Codec interface {
+	// ID returns the identifier of the codec
+	ID() CodecID
+	// Name returns the name of the codec, such as "zstd"
+	Name() string
+	// Compress appends the compression of src to dst
+	Compress(dst, src []byte, level Level) ([]byte, error)
+	// Decompress appends the decompression of src to dst
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+var (
+	codecMutex sync.RWMutex
+	codecs     [MaxCodecID + 1]Codec
+)
+
+func init() {
+	for _, codec := range []Codec{noneCodec{}, zlibCodec{}, gzipCodec{}, flateCodec{}, snappyCodec{}, &zstdCodec{}} {
+		RegisterCodec(codec)
+	}
+}
+
+// RegisterCodec registers a codec under its ID, it panics if the ID is out of range or already registered
+func RegisterCodec(codec Codec) {
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+
+	id := codec.ID()
+	if id > MaxCodecID {
+		panic("compress: codec id out of range")
+	}
+	if codecs[id] != nil {
+		panic("compress: codec " + codecs[id].Name() + " already registered")
+	}
+	codecs[id] = codec
+}
+
+// GetCodec returns the codec registered under id
+func GetCodec(id CodecID) (Codec, error) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+
+	if id > MaxCodecID || codecs[id] == nil {
+		return nil, errors.Wrapf(ErrUnknownCodec, "codec id %d", id)
+	}
+	return codecs[id], nil
+}
+
+type noneCodec struct{}
+
+func (noneCodec) ID() CodecID  { return None }
+func (noneCodec) Name() string { return "none" }
+
+func (noneCodec) Compress(dst, src []byte, _ Level) ([]byte, error) {
+	return append(dst, src...), nil
+}
+
+func (noneCodec) Decompress(dst, src []byte) ([]byte, error) {
+	return append(dst, src...), nil
+}
+
+type zlibCodec struct{}
+
+func (zlibCodec) ID() CodecID  { return Zlib }
+func (zlibCodec) Name() string { return "zlib" }
+
+func (zlibCodec) Compress(dst, src []byte, level Level) ([]byte, error) {
+	return compressStream(dst, src, func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriterLevel(w, deflateLevel(level))
+	})
+}
+
+func (zlibCodec) Decompress(dst, src []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, errors.Wrap(err, "create zlib reader failed")
+	}
+	return decompressStream(dst, reader)
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) ID() CodecID  { return Gzip }
+func (gzipCodec) Name() string { return "gzip" }
+
+func (gzipCodec) Compress(dst, src []byte, level Level) ([]byte, error) {
+	return compressStream(dst, src, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, deflateLevel(level))
+	})
+}
+
+func (gzipCodec) Decompress(dst, src []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, errors.Wrap(err, "create gzip reader failed")
+	}
+	return decompressStream(dst, reader)
+}
+
+type flateCodec struct{}
+
+func (flateCodec) ID() CodecID  { return Flate }
+func (flateCodec) Name() string { return "flate" }
+
+func (flateCodec) Compress(dst, src []byte, level Level) ([]byte, error) {
+	return compressStream(dst, src, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, deflateLevel(level))
+	})
+}
+
+func (flateCodec) Decompress(dst, src []byte) ([]byte, error) {
+	return decompressStream(dst, flate.NewReader(bytes.NewReader(src)))
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) ID() CodecID  { return Snappy }
+func (snappyCodec) Name() string { return "snappy" }
+
+func (snappyCodec) Compress(dst, src []byte, _ Level) ([]byte, error) {
+	n := snappy.MaxEncodedLen(len(src))
+	if n < 0 {
+		return nil, errors.New("snappy source too large")
+	}
+	dst = slices.Grow(dst, n)
+	encoded := snappy.Encode(dst[len(dst):len(dst)+n], src)
+	return dst[:len(dst)+len(encoded)], nil
+}
+
+func (snappyCodec) Decompress(dst, src []byte) ([]byte, error) {
+	n, err := snappy.DecodedLen(src)
+	if err != nil {
+		return nil, errors.Wrap(err, "read snappy length failed")
+	}
+	dst = slices.Grow(dst, n)
+	decoded, err := snappy.Decode(dst[len(dst):len(dst)+n], src)
+	if err != nil {
+		return nil, errors.Wrap(err, "snappy decode failed")
+	}
+	return dst[:len(dst)+len(decoded)], nil
+}
+
+// zstdCodec shares its encoders and decoder, which are safe for concurrent EncodeAll and DecodeAll
+type zstdCodec struct {
+	once     sync.Once
+	encoders [LevelDefault + 1]*zstd.Encoder
+	decoder  *zstd.Decoder
+	err      error
+}
+
+func (*zstdCodec) ID() CodecID  { return Zstd }
+func (*zstdCodec) Name() string { return "zstd" }
+
+func (c *zstdCodec) init() error {
+	c.once.Do(func() {
+		levels := [...]zstd.EncoderLevel{LevelFast: zstd.SpeedFastest, LevelDefault: zstd.SpeedDefault}
+		for level, encoderLevel := range levels {
+			if c.encoders[level], c.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel)); c.err != nil {
+				c.err = errors.Wrap(c.err, "create zstd encoder failed")
+				return
+			}
+		}
+		if c.decoder, c.err = zstd.NewReader(nil); c.err != nil {
+			c.err = errors.Wrap(c.err, "create zstd decoder failed")
+		}
+	})
+	return c.err
+}
+
+func (c *zstdCodec) Compress(dst, src []byte, level Level) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	if level < LevelFast || level > LevelDefault {
+		level = LevelDefault
+	}
+	return c.encoders[level].EncodeAll(src, dst), nil
+}
+
+func (c *zstdCodec) Decompress(dst, src []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	out, err := c.decoder.DecodeAll(src, dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "zstd decode failed")
+	}
+	return out, nil
+}
+
+// deflateLevel returns the level of the deflate based codecs
+func deflateLevel(level Level) int {
+	if level == LevelFast {
+		return flate.BestSpeed
+	}
+	return flate.DefaultCompression
+}
+
+// compressStream appends the compression of src by the writer of newWriter to dst
+func compressStream(dst, src []byte, newWriter func(w io.Writer) (io.WriteCloser, error)) ([]byte, error) {
+	buffer := bytes.NewBuffer(dst)
+	writer, err := newWriter(buffer)
+	if err != nil {
+		return nil, errors.Wrap(err, "create compressor failed")
+	}
+
+	if _, err := writer.Write(src); err != nil {
+		writer.Close()
+		return nil, errors.Wrap(err, "write to compressor failed")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "close compressor failed")
+	}
+	return buffer.Bytes(), nil
+}
+
+// decompressStream appends the data read from reader to dst
+func decompressStream(dst []byte, reader io.ReadCloser) ([]byte, error) {
+	defer reader.Close()
+
+	buffer := bytes.NewBuffer(dst)
+	if _, err := buffer.ReadFrom(reader); err != nil {
+		return nil, errors.Wrap(err, "read from decompressor failed")
+	}
+	return buffer.Bytes(), nil
+}
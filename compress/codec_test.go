@@ -0,0 +1,92 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var builtinCodecs = []CodecID{None, Zlib, Gzip, Flate, Snappy, Zstd}
+
+func TestCodecs(t *testing.T) {
+	data := bytes.Repeat([]byte("vulcan codec test data "), 1000)
+
+	for _, id := range builtinCodecs {
+		codec, err := GetCodec(id)
+		require.NoError(t, err)
+		assert.Equal(t, id, codec.ID())
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, level := range []Level{LevelFast, LevelDefault} {
+				prefix := []byte("prefix")
+				compressed, err := codec.Compress(prefix, data, level)
+				require.NoError(t, err)
+				assert.Equal(t, []byte("prefix"), compressed[:6], "Compress must append to dst")
+				if id != None {
+					assert.Less(t, len(compressed), len(data)/4)
+				}
+
+				decompressed, err := codec.Decompress([]byte("out:"), compressed[6:])
+				require.NoError(t, err)
+				assert.Equal(t, append([]byte("out:"), data...), decompressed, "Decompress must append to dst")
+			}
+
+			empty, err := codec.Compress(nil, nil, LevelDefault)
+			require.NoError(t, err)
+			decompressed, err := codec.Decompress(nil, empty)
+			require.NoError(t, err)
+			assert.Empty(t, decompressed)
+
+			if id != None {
+				_, err = codec.Decompress(nil, []byte{0xff, 0xfe, 0xfd, 0xfc, 0xfb})
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+type reverseCodec struct{}
+
+func (reverseCodec) ID() CodecID  { return MaxCodecID }
+func (reverseCodec) Name() string { return "reverse" }
+
+func (reverseCodec) Compress(dst, src []byte, _ Level) ([]byte, error) {
+	for i := len(src) - 1; i >= 0; i-- {
+		dst = append(dst, src[i])
+	}
+	return dst, nil
+}
+
+func (c reverseCodec) Decompress(dst, src []byte) ([]byte, error) {
+	return c.Compress(dst, src, LevelDefault)
+}
+
+type outOfRangeCodec struct{ reverseCodec }
+
+func (outOfRangeCodec) ID() CodecID { return MaxCodecID + 1 }
+
+func TestRegisterCodec(t *testing.T) {
+	_, err := GetCodec(MaxCodecID)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+	_, err = GetCodec(MaxCodecID + 1)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+
+	RegisterCodec(reverseCodec{})
+	codec, err := GetCodec(MaxCodecID)
+	require.NoError(t, err)
+	assert.Equal(t, "reverse", codec.Name())
+
+	data := bytes.Repeat([]byte("abc"), testWeakThreshold)
+	encoded, err := EncodeWith(MaxCodecID, data)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xf1), encoded[0])
+	decoded, err := Decompress(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	assert.Panics(t, func() { RegisterCodec(reverseCodec{}) })
+	assert.Panics(t, func() { RegisterCodec(zlibCodec{}) })
+	assert.Panics(t, func() { RegisterCodec(outOfRangeCodec{}) })
+}
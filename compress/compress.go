@@ -1,8 +1,6 @@
 package compress
 
 import (
-	"bytes"
-	"compress/zlib"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -12,21 +10,7 @@ var (
 	compressMutex         sync.RWMutex
 	defaultWeakCompress   = 10 << 10  // 10KB
 	defaultStrongCompress = 512 << 10 // 512KB
-	defaultWeakLevel      = zlib.BestSpeed
-	defaultStrongLevel    = zlib.DefaultCompression
-)
-
-var (
-	compressBufferPool = sync.Pool{
-		New: func() interface{} {
-			return new(bytes.Buffer)
-		},
-	}
-	decompressBufferPool = sync.Pool{
-		New: func() interface{} {
-			return new(bytes.Buffer)
-		},
-	}
+	defaultCodec          = Zlib
 )
 
 // Init init compress params
@@ -44,91 +28,110 @@ func Init(weak, strong int) {
 	}
 }
 
+// SetDefaultCodec sets the codec used by Encode, zlib by default
+func SetDefaultCodec(id CodecID) error {
+	if _, err := GetCodec(id); err != nil {
+		return err
+	}
+
+	compressMutex.Lock()
+	defer compressMutex.Unlock()
+	defaultCodec = id
+	return nil
+}
+
 // Compress auto select compress strategy based on data length
-// return compressed data, whether compression is performed, error info
+// return headerless zlib data, whether compression is performed, error info.
+// Encode is preferred, its output records the codec used and can be migrated to other codecs.
 func Compress(data []byte) ([]byte, bool, error) {
-	dataLen := len(data)
-	if dataLen == 0 {
+	if len(data) == 0 {
 		return []byte{}, false, nil
 	}
 
-	compressMutex.RLock()
-	weakThreshold := defaultWeakCompress
-	strongThreshold := defaultStrongCompress
-	compressMutex.RUnlock()
-
-	if dataLen < weakThreshold {
+	compress, level := compressLevel(len(data))
+	if !compress {
 		return data, false, nil
 	}
 
-	level := defaultWeakLevel
-	if dataLen >= strongThreshold {
-		level = defaultStrongLevel
-	}
-
-	compressed, err := zlibCompress(data, level)
+	compressed, err := zlibCodec{}.Compress(nil, data, level)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "compression failed")
 	}
 	return compressed, true, nil
 }
 
-// Decompress decompress data
-func Decompress(data []byte) ([]byte, error) {
-	if len(data) == 0 {
-		return []byte{}, nil
-	}
+// Encode compresses data with the default codec, see EncodeWith
+func Encode(data []byte) ([]byte, error) {
+	compressMutex.RLock()
+	id := defaultCodec
+	compressMutex.RUnlock()
 
-	decompressed, err := zlibDecompress(data)
-	if err != nil {
-		return nil, errors.Wrap(err, "decompression failed")
-	}
-	return decompressed, nil
+	return EncodeWith(id, data)
 }
 
-func zlibCompress(data []byte, level int) ([]byte, error) {
-	if level < zlib.BestSpeed || level > zlib.BestCompression {
-		level = zlib.DefaultCompression
+// EncodeWith compresses data with the codec id and prefixes it with a header recording the codec,
+// data shorter than the weak threshold is stored without compression.
+// The result is read back by Decompress.
+func EncodeWith(id CodecID, data []byte) ([]byte, error) {
+	compress, level := compressLevel(len(data))
+	if !compress {
+		id = None
 	}
 
-	buffer := compressBufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		buffer.Reset()
-		compressBufferPool.Put(buffer)
-	}()
+	codec, err := GetCodec(id)
+	if err != nil {
+		return nil, err
+	}
 
-	writer, err := zlib.NewWriterLevel(buffer, level)
+	encoded, err := codec.Compress([]byte{header(id)}, data, level)
 	if err != nil {
-		return nil, errors.Wrapf(err, "create zlib writer failed (level %d)", level)
+		return nil, errors.Wrapf(err, "%s compression failed", codec.Name())
 	}
+	return encoded, nil
+}
 
-	if _, err := writer.Write(data); err != nil {
-		writer.Close()
-		return nil, errors.Wrap(err, "write to compressor failed")
+// Decompress decompress data produced by Encode, or the headerless zlib data of Compress
+func Decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return []byte{}, nil
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, errors.Wrap(err, "close compressor failed")
+	if isZlib(data) {
+		decompressed, err := zlibCodec{}.Decompress(nil, data)
+		if err != nil {
+			return nil, errors.Wrap(err, "decompression failed")
+		}
+		return decompressed, nil
 	}
 
-	return buffer.Bytes(), nil
-}
+	id, err := parseHeader(data[0])
+	if err != nil {
+		return nil, err
+	}
+	codec, err := GetCodec(id)
+	if err != nil {
+		return nil, err
+	}
 
-func zlibDecompress(data []byte) ([]byte, error) {
-	reader, err := zlib.NewReader(bytes.NewReader(data))
+	decompressed, err := codec.Decompress(nil, data[1:])
 	if err != nil {
-		return nil, errors.Wrap(err, "create zlib reader failed")
+		return nil, errors.Wrapf(err, "%s decompression failed", codec.Name())
 	}
-	defer reader.Close()
+	return decompressed, nil
+}
 
-	buffer := decompressBufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		buffer.Reset()
-		decompressBufferPool.Put(buffer)
-	}()
+// compressLevel returns whether data of length n is compressed and the level used
+func compressLevel(n int) (bool, Level) {
+	compressMutex.RLock()
+	weakThreshold := defaultWeakCompress
+	strongThreshold := defaultStrongCompress
+	compressMutex.RUnlock()
 
-	if _, err := buffer.ReadFrom(reader); err != nil {
-		return nil, errors.Wrap(err, "read from decompressor failed")
+	if n == 0 || n < weakThreshold {
+		return false, LevelFast
+	}
+	if n >= strongThreshold {
+		return true, LevelDefault
 	}
-	return buffer.Bytes(), nil
+	return true, LevelFast
 }
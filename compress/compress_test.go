@@ -95,6 +95,76 @@ func TestErrorConditions(t *testing.T) {
 	})
 }
 
+func TestEncode(t *testing.T) {
+	small := []byte("hello world")
+	large := bytes.Repeat([]byte("vulcan encode test "), testStrongThreshold/10)
+
+	for _, id := range builtinCodecs {
+		t.Run(fmt.Sprint(id), func(t *testing.T) {
+			encoded, err := EncodeWith(id, small)
+			require.NoError(t, err)
+			assert.Equal(t, append([]byte{header(None)}, small...), encoded, "small data is stored")
+
+			encoded, err = EncodeWith(id, large)
+			require.NoError(t, err)
+			assert.Equal(t, header(id), encoded[0])
+			decoded, err := Decompress(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, large, decoded)
+		})
+	}
+
+	encoded, err := EncodeWith(Zstd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{header(None)}, encoded)
+	decoded, err := Decompress(encoded)
+	require.NoError(t, err)
+	assert.Empty(t, decoded)
+
+	_, err = EncodeWith(MaxCodecID-1, large)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+}
+
+func TestSetDefaultCodec(t *testing.T) {
+	defer func() { require.NoError(t, SetDefaultCodec(Zlib)) }()
+
+	data := bytes.Repeat([]byte{0x01}, testWeakThreshold)
+	encoded, err := Encode(data)
+	require.NoError(t, err)
+	assert.Equal(t, header(Zlib), encoded[0])
+
+	require.NoError(t, SetDefaultCodec(Zstd))
+	encoded, err = Encode(data)
+	require.NoError(t, err)
+	assert.Equal(t, header(Zstd), encoded[0])
+
+	assert.ErrorIs(t, SetDefaultCodec(MaxCodecID-1), ErrUnknownCodec)
+	encoded, err = Encode(data)
+	require.NoError(t, err)
+	assert.Equal(t, header(Zstd), encoded[0], "a failed SetDefaultCodec must keep the codec")
+}
+
+func TestDecompressMigration(t *testing.T) {
+	data := randBytes(testStrongThreshold)
+
+	legacy, didCompress, err := Compress(data)
+	require.NoError(t, err)
+	require.True(t, didCompress)
+	encoded, err := EncodeWith(Zstd, data)
+	require.NoError(t, err)
+
+	for _, blob := range [][]byte{legacy, encoded} {
+		decoded, err := Decompress(blob)
+		require.NoError(t, err)
+		assert.Equal(t, data, decoded)
+	}
+
+	_, err = Decompress([]byte{header(MaxCodecID - 1), 0x00})
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+	_, err = Decompress([]byte{header(Zstd), 0x00, 0x01})
+	assert.Error(t, err)
+}
+
 func TestConcurrentSafety(t *testing.T) {
 	var wg sync.WaitGroup
 	const goroutines = 10
@@ -0,0 +1,38 @@
+package compress
+
+import (
+	"github.com/pkg/errors"
+)
+
+// The header byte of encoded data holds the codec ID in its high nibble
+// and the header version in its low nibble:
+//
+//	codec    4 bits
+//	version  4 bits
+//
+// A zlib stream starts with a byte whose low nibble is 8 (the deflate method),
+// so the header version must never be 8 for headerless zlib data to stay recognizable.
+const headerVersion = 1
+
+var ErrInvalidHeader = errors.New("invalid compress header")
+
+// header returns the header byte of data encoded by the codec id
+func header(id CodecID) byte {
+	return byte(id)<<4 | headerVersion
+}
+
+// parseHeader returns the codec ID of a header byte
+func parseHeader(b byte) (CodecID, error) {
+	if b&0x0f != headerVersion {
+		return 0, errors.Wrapf(ErrInvalidHeader, "unsupported version %d", b&0x0f)
+	}
+	return CodecID(b >> 4), nil
+}
+
+// isZlib checks if data starts with a valid zlib header (RFC 1950): deflate method,
+// window size up to 32KB and a check value making the first two bytes a multiple of 31
+func isZlib(data []byte) bool {
+	return len(data) >= 2 &&
+		data[0]&0x0f == 8 && data[0]>>4 <= 7 &&
+		(uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
@@ -0,0 +1,42 @@
+package compress
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	for id := None; id <= MaxCodecID; id++ {
+		b := header(id)
+		got, err := parseHeader(b)
+		require.NoError(t, err)
+		assert.Equal(t, id, got)
+		assert.False(t, isZlib([]byte{b, 0x9c}), "header %#x must not look like zlib", b)
+	}
+
+	_, err := parseHeader(0x12)
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+	_, err = parseHeader(0x78)
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestIsZlib(t *testing.T) {
+	for level := zlib.HuffmanOnly; level <= zlib.BestCompression; level++ {
+		var buf bytes.Buffer
+		w, err := zlib.NewWriterLevel(&buf, level)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("legacy zlib data"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.True(t, isZlib(buf.Bytes()), "level %d", level)
+	}
+
+	assert.False(t, isZlib(nil))
+	assert.False(t, isZlib([]byte{0x78}))
+	assert.False(t, isZlib([]byte{0x78, 0x00}), "invalid check value")
+	assert.False(t, isZlib([]byte{0x88, 0x1e}), "window size above 32KB")
+}
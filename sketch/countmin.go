@@ -0,0 +1,100 @@
+// Package sketch provides probabilistic frequency summaries of streams, such as finding hot keys.
+// Keys are hashed with the hashers of the bloom package.
+package sketch
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bloom"
+)
+
+var ErrIncompatible = errors.New("incompatible sketches")
+
+// CountMinSketch represents a thread-safe count-min sketch estimating the count of every key of a stream.
+// Estimates never underestimate, and overestimate by at most epsilon*Total() with probability 1-delta.
+type CountMinSketch[T any] struct {
+	counters []atomic.Uint64 // depth rows of width counters
+	width    uint32
+	depth    uint32
+	hash     bloom.Hasher[T]
+	total    atomic.Uint64
+}
+
+// NewCountMinSketch create count-min sketch of keys hashed by hash, for example bloom.StringHasher
+// epsilon: error bound relative to the total count (0 < epsilon < 1)
+// delta: probability of exceeding the error bound (0 < delta < 1)
+func NewCountMinSketch[T any](epsilon, delta float64, hash bloom.Hasher[T]) *CountMinSketch[T] {
+	width := uint32(math.Ceil(math.E / epsilon))
+	depth := uint32(math.Ceil(math.Log(1 / delta)))
+	return NewCountMinSketchWithSize(width, max(depth, 1), hash)
+}
+
+// NewCountMinSketchWithSize create count-min sketch of depth rows of width counters
+func NewCountMinSketchWithSize[T any](width, depth uint32, hash bloom.Hasher[T]) *CountMinSketch[T] {
+	if width == 0 || depth == 0 {
+		panic("count-min sketch size must be positive")
+	}
+	return &CountMinSketch[T]{
+		counters: make([]atomic.Uint64, uint64(width)*uint64(depth)),
+		width:    width,
+		depth:    depth,
+		hash:     hash,
+	}
+}
+
+// Add adds count occurrences of key and returns its new estimate
+func (s *CountMinSketch[T]) Add(key T, count uint64) uint64 {
+	s.total.Add(count)
+	estimate := uint64(math.MaxUint64)
+	h1, h2 := s.hash(key)
+	for row := uint32(0); row < s.depth; row++ {
+		estimate = min(estimate, s.counters[s.index(row, h1, h2)].Add(count))
+	}
+	return estimate
+}
+
+// Estimate returns the estimated count of key
+func (s *CountMinSketch[T]) Estimate(key T) uint64 {
+	estimate := uint64(math.MaxUint64)
+	h1, h2 := s.hash(key)
+	for row := uint32(0); row < s.depth; row++ {
+		estimate = min(estimate, s.counters[s.index(row, h1, h2)].Load())
+	}
+	return estimate
+}
+
+// Merge adds the counts of other, which must have the same width and depth and hash keys the same way
+func (s *CountMinSketch[T]) Merge(other *CountMinSketch[T]) error {
+	if s.width != other.width || s.depth != other.depth {
+		return errors.Wrapf(ErrIncompatible, "%dx%d and %dx%d", s.width, s.depth, other.width, other.depth)
+	}
+	for i := range s.counters {
+		if v := other.counters[i].Load(); v != 0 {
+			s.counters[i].Add(v)
+		}
+	}
+	s.total.Add(other.total.Load())
+	return nil
+}
+
+// Total returns the sum of the counts added
+func (s *CountMinSketch[T]) Total() uint64 {
+	return s.total.Load()
+}
+
+// Width returns the number of counters per row
+func (s *CountMinSketch[T]) Width() uint32 {
+	return s.width
+}
+
+// Depth returns the number of rows
+func (s *CountMinSketch[T]) Depth() uint32 {
+	return s.depth
+}
+
+// index returns the counter of key in row, rows use the Kirsch–Mitzenmacher probes of the bloom filters
+func (s *CountMinSketch[T]) index(row uint32, h1, h2 uint64) uint64 {
+	return uint64(row)*uint64(s.width) + (h1+uint64(row)*h2)%uint64(s.width)
+}
@@ -0,0 +1,95 @@
+package sketch
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bloom"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	s := NewCountMinSketch(0.001, 0.01, bloom.StringHasher)
+	assert.Equal(t, uint32(2719), s.Width())
+	assert.Equal(t, uint32(5), s.Depth())
+	assert.Zero(t, s.Estimate("guild:1"))
+
+	assert.Equal(t, uint64(3), s.Add("guild:1", 3))
+	assert.Equal(t, uint64(4), s.Add("guild:1", 1))
+	s.Add("guild:2", 10)
+	assert.Equal(t, uint64(4), s.Estimate("guild:1"))
+	assert.Equal(t, uint64(10), s.Estimate("guild:2"))
+	assert.Equal(t, uint64(14), s.Total())
+
+	assert.Panics(t, func() { NewCountMinSketchWithSize(0, 1, bloom.StringHasher) })
+}
+
+func TestCountMinSketchErrorBound(t *testing.T) {
+	const epsilon = 0.001
+	s := NewCountMinSketch(epsilon, 0.01, bloom.Int64Hasher)
+	rnd := rand.New(rand.NewSource(1))
+	counts := make(map[int64]uint64)
+	// zipf-like stream of player IDs
+	zipf := rand.NewZipf(rnd, 1.2, 1, 100_000)
+	for i := 0; i < 200_000; i++ {
+		id := int64(zipf.Uint64())
+		counts[id]++
+		s.Add(id, 1)
+	}
+
+	bound := uint64(epsilon * float64(s.Total()))
+	exceeded := 0
+	for id, count := range counts {
+		estimate := s.Estimate(id)
+		require.GreaterOrEqual(t, estimate, count, "never underestimates")
+		if estimate-count > bound {
+			exceeded++
+		}
+	}
+	assert.LessOrEqual(t, float64(exceeded)/float64(len(counts)), 0.01)
+}
+
+func TestCountMinSketchMerge(t *testing.T) {
+	a := NewCountMinSketch(0.01, 0.01, bloom.StringHasher)
+	b := NewCountMinSketch(0.01, 0.01, bloom.StringHasher)
+	a.Add("x", 5)
+	b.Add("x", 7)
+	b.Add("y", 2)
+
+	require.NoError(t, a.Merge(b))
+	assert.Equal(t, uint64(12), a.Estimate("x"))
+	assert.Equal(t, uint64(2), a.Estimate("y"))
+	assert.Equal(t, uint64(14), a.Total())
+	assert.Equal(t, uint64(7), b.Estimate("x"), "other is unchanged")
+
+	assert.ErrorIs(t, a.Merge(NewCountMinSketch(0.1, 0.01, bloom.StringHasher)), ErrIncompatible)
+}
+
+func TestCountMinSketchConcurrency(t *testing.T) {
+	s := NewCountMinSketch(0.001, 0.01, bloom.StringHasher)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s.Add(fmt.Sprintf("key-%d", i%10), 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(8000), s.Total())
+	for i := 0; i < 10; i++ {
+		assert.GreaterOrEqual(t, s.Estimate(fmt.Sprintf("key-%d", i)), uint64(800))
+	}
+}
+
+func BenchmarkCountMinSketchAdd(b *testing.B) {
+	s := NewCountMinSketch(0.0001, 0.01, bloom.Int64Hasher)
+	for i := 0; i < b.N; i++ {
+		s.Add(int64(i&0xffff), 1)
+	}
+}
@@ -0,0 +1,241 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	MinHyperLogLogPrecision   = 4
+	MaxHyperLogLogPrecision   = 18
+	RedisHyperLogLogPrecision = 14 // the precision of PFADD and PFCOUNT
+
+	hllSeed          = 0xadc83b19 // the MurmurHash64A seed of Redis
+	hllMaxSparseRank = 32         // the largest register value of the sparse encoding
+)
+
+// HyperLogLog represents a thread-safe HyperLogLog estimating the number of distinct elements added.
+// The standard error is 1.04/sqrt(2^precision), 0.81% at the Redis precision of 14.
+//
+// Elements are hashed and estimated like Redis does, and at precision 14 MarshalBinary produces a value
+// that PFCOUNT and PFMERGE accept after a SET, while UnmarshalBinary accepts the result of a GET on a key
+// written by PFADD. Small sets use a sparse representation of the registers.
+type HyperLogLog struct {
+	mutex     sync.RWMutex
+	p         uint8
+	sparse    map[uint32]uint8 // the non-zero registers, nil once dense
+	registers []uint8          // nil while sparse
+}
+
+// NewHyperLogLog create HyperLogLog of 2^precision registers,
+// precision is from MinHyperLogLogPrecision to MaxHyperLogLogPrecision
+func NewHyperLogLog(precision int) *HyperLogLog {
+	if precision < MinHyperLogLogPrecision || precision > MaxHyperLogLogPrecision {
+		panic("hyperloglog precision out of range")
+	}
+	return &HyperLogLog{
+		p:      uint8(precision),
+		sparse: make(map[uint32]uint8),
+	}
+}
+
+// Add add element, returns true if the estimate may have changed like PFADD
+func (h *HyperLogLog) Add(data []byte) bool {
+	index, rank := h.position(data)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.update(index, rank)
+}
+
+// AddString add string element, returns true if the estimate may have changed like PFADD
+func (h *HyperLogLog) AddString(data string) bool {
+	return h.Add(unsafe.Slice(unsafe.StringData(data), len(data)))
+}
+
+// Count returns the estimated number of distinct elements
+func (h *HyperLogLog) Count() uint64 {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var histogram [64]int
+	if h.registers == nil {
+		histogram[0] = h.m() - len(h.sparse)
+		for _, v := range h.sparse {
+			histogram[v]++
+		}
+	} else {
+		for _, v := range h.registers {
+			histogram[v]++
+		}
+	}
+	return estimateCardinality(&histogram, h.p)
+}
+
+// Merge adds the elements of other, which must have the same precision
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h == other {
+		return nil
+	}
+	if h.p != other.p {
+		return errors.Wrapf(ErrIncompatible, "hyperloglog precisions %d and %d", h.p, other.p)
+	}
+
+	// copy first, so that merging two HyperLogLogs into each other cannot deadlock
+	other.mutex.RLock()
+	sparse, registers := make(map[uint32]uint8, len(other.sparse)), []uint8(nil)
+	if other.registers == nil {
+		for index, v := range other.sparse {
+			sparse[index] = v
+		}
+	} else {
+		registers = append(registers, other.registers...)
+	}
+	other.mutex.RUnlock()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for index, v := range sparse {
+		h.update(index, v)
+	}
+	for index, v := range registers {
+		if v != 0 {
+			h.update(uint32(index), v)
+		}
+	}
+	return nil
+}
+
+// Precision returns the precision of the HyperLogLog
+func (h *HyperLogLog) Precision() int {
+	return int(h.p)
+}
+
+// position returns the register of the element and its rank, the position of the first 1 bit of the hash
+func (h *HyperLogLog) position(data []byte) (uint32, uint8) {
+	hash := murmurHash64A(data, hllSeed)
+	index := uint32(hash & (1<<h.p - 1))
+	q := 64 - h.p
+	// the guard bit bounds the rank to q+1
+	rank := bits.TrailingZeros64(hash>>h.p|1<<q) + 1
+	return index, uint8(rank)
+}
+
+// update raises the register at index to rank
+func (h *HyperLogLog) update(index uint32, rank uint8) bool {
+	if h.registers == nil {
+		if rank <= h.sparse[index] {
+			return false
+		}
+		if rank <= hllMaxSparseRank && len(h.sparse) < h.maxSparse() {
+			h.sparse[index] = rank
+			return true
+		}
+		h.toDense()
+	}
+	if rank <= h.registers[index] {
+		return false
+	}
+	h.registers[index] = rank
+	return true
+}
+
+// toDense switches to the dense representation
+func (h *HyperLogLog) toDense() {
+	h.registers = make([]uint8, h.m())
+	for index, v := range h.sparse {
+		h.registers[index] = v
+	}
+	h.sparse = nil
+}
+
+func (h *HyperLogLog) m() int {
+	return 1 << h.p
+}
+
+// maxSparse returns the number of non-zero registers above which the dense representation is smaller
+func (h *HyperLogLog) maxSparse() int {
+	return h.m() / 8
+}
+
+// estimateCardinality implements the estimator of Otmar Ertl ("New cardinality estimation algorithms
+// for HyperLogLog sketches", 2017) from the histogram of the register values, as Redis does
+func estimateCardinality(histogram *[64]int, p uint8) uint64 {
+	m := float64(int(1) << p)
+	q := int(64 - p)
+
+	z := m * hllTau((m-float64(histogram[q+1]))/m)
+	for j := q; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	return uint64(math.Round(0.5 / math.Ln2 * m * m / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if prev == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if prev == z {
+			return z / 3
+		}
+	}
+}
+
+// murmurHash64A is the 64-bit MurmurHash2 used by Redis for HyperLogLog
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const (
+		m = 0xc6a4a7935bd1e995
+		r = 47
+	)
+	h := seed ^ uint64(len(data))*m
+
+	for len(data) >= 8 {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+		data = data[8:]
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
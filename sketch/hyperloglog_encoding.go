@@ -0,0 +1,202 @@
+package sketch
+
+import (
+	"encoding"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidEncoding = errors.New("invalid sketch encoding")
+
+// Binary format of a marshaled HyperLogLog, the Redis HYLL string:
+//
+//	magic      4 bytes, "HYLL"
+//	encoding   1 byte, 0 for dense and 1 for sparse
+//	precision  1 byte, 0 for the Redis precision of 14 (unused by Redis)
+//	unused     2 bytes
+//	card       8 bytes, the cached cardinality of Redis, always marked invalid
+//	registers  dense: 6 bits per register, little-endian bit order
+//	           sparse: run-length opcodes
+//	             00xxxxxx           ZERO, xxxxxx+1 zero registers
+//	             01xxxxxx yyyyyyyy  XZERO, xxxxxxyyyyyyyy+1 zero registers
+//	             1vvvvvxx           VAL, xx+1 registers of value vvvvv+1
+const (
+	hllMagic        = "HYLL"
+	hllHeaderSize   = 16
+	hllDense        = 0
+	hllSparse       = 1
+	hllRegisterBits = 6
+	hllRegisterMax  = 1<<hllRegisterBits - 1
+
+	hllOpcodeXZero = 0x40
+	hllOpcodeVal   = 0x80
+	hllMaxZero     = 64
+	hllMaxXZero    = 16384
+	hllMaxValRun   = 4
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*HyperLogLog)(nil)
+	_ encoding.BinaryUnmarshaler = (*HyperLogLog)(nil)
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	data := make([]byte, hllHeaderSize, hllHeaderSize+h.denseSize())
+	copy(data, hllMagic)
+	if h.p != RedisHyperLogLogPrecision {
+		data[5] = h.p
+	}
+	data[15] = 1 << 7 // the cached cardinality must be recomputed
+
+	if h.registers != nil {
+		data[4] = hllDense
+		data = data[:hllHeaderSize+h.denseSize()]
+		registers := data[hllHeaderSize:]
+		for index, v := range h.registers {
+			setDenseRegister(registers, index, v)
+		}
+		return data, nil
+	}
+
+	data[4] = hllSparse
+	indexes := make([]uint32, 0, len(h.sparse))
+	for index := range h.sparse {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+
+	next := 0 // the first register not encoded yet
+	for i := 0; i < len(indexes); {
+		index := int(indexes[i])
+		data = appendZeroRun(data, index-next)
+
+		// a run of consecutive registers of the same value
+		v, run := h.sparse[indexes[i]], 1
+		for i+run < len(indexes) && int(indexes[i+run]) == index+run && h.sparse[indexes[i+run]] == v {
+			run++
+		}
+		for left := run; left > 0; left -= hllMaxValRun {
+			data = append(data, hllOpcodeVal|(v-1)<<2|byte(min(left, hllMaxValRun)-1))
+		}
+		i += run
+		next = index + run
+	}
+	return appendZeroRun(data, h.m()-next), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the HyperLogLog.
+// It can be called on a zero HyperLogLog.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < hllHeaderSize || string(data[:4]) != hllMagic {
+		return errors.Wrap(ErrInvalidEncoding, "not a HYLL string")
+	}
+	p := data[5]
+	if p == 0 {
+		p = RedisHyperLogLogPrecision
+	}
+	if p < MinHyperLogLogPrecision || p > MaxHyperLogLogPrecision {
+		return errors.Wrapf(ErrInvalidEncoding, "precision %d out of range", p)
+	}
+	decoded := &HyperLogLog{p: p}
+	payload := data[hllHeaderSize:]
+
+	switch data[4] {
+	case hllDense:
+		if len(payload) != decoded.denseSize() {
+			return errors.Wrapf(ErrInvalidEncoding, "dense registers of %d bytes, expected %d", len(payload), decoded.denseSize())
+		}
+		decoded.registers = make([]uint8, decoded.m())
+		for index := range decoded.registers {
+			decoded.registers[index] = denseRegister(payload, index)
+		}
+	case hllSparse:
+		if err := decoded.decodeSparse(payload); err != nil {
+			return err
+		}
+		if len(decoded.sparse) > decoded.maxSparse() {
+			decoded.toDense()
+		}
+	default:
+		return errors.Wrapf(ErrInvalidEncoding, "unknown encoding %d", data[4])
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.p, h.sparse, h.registers = decoded.p, decoded.sparse, decoded.registers
+	return nil
+}
+
+func (h *HyperLogLog) denseSize() int {
+	return (h.m()*hllRegisterBits + 7) / 8
+}
+
+// decodeSparse sets the registers from sparse opcodes
+func (h *HyperLogLog) decodeSparse(payload []byte) error {
+	h.sparse = make(map[uint32]uint8)
+	index := 0
+	for i := 0; i < len(payload); i++ {
+		op := payload[i]
+		var run int
+		var v uint8
+		switch {
+		case op&hllOpcodeVal != 0:
+			run, v = int(op&3)+1, op>>2&0x1f+1
+		case op&hllOpcodeXZero != 0:
+			if i++; i == len(payload) {
+				return errors.Wrap(ErrInvalidEncoding, "truncated XZERO opcode")
+			}
+			run = int(op&0x3f)<<8 | int(payload[i]) + 1
+		default:
+			run = int(op&0x3f) + 1
+		}
+		if index+run > h.m() {
+			return errors.Wrapf(ErrInvalidEncoding, "sparse opcodes exceed %d registers", h.m())
+		}
+		for r := index; v != 0 && r < index+run; r++ {
+			h.sparse[uint32(r)] = v
+		}
+		index += run
+	}
+	if index != h.m() {
+		return errors.Wrapf(ErrInvalidEncoding, "sparse opcodes cover %d registers, expected %d", index, h.m())
+	}
+	return nil
+}
+
+// appendZeroRun appends the opcodes of count zero registers
+func appendZeroRun(data []byte, count int) []byte {
+	for count > 0 {
+		run := min(count, hllMaxXZero)
+		if run <= hllMaxZero {
+			data = append(data, byte(run-1))
+		} else {
+			data = append(data, hllOpcodeXZero|byte((run-1)>>8), byte(run-1))
+		}
+		count -= run
+	}
+	return data
+}
+
+func denseRegister(registers []byte, index int) uint8 {
+	bit := index * hllRegisterBits
+	b, shift := bit/8, uint(bit%8)
+	v := uint(registers[b]) >> shift
+	if b+1 < len(registers) {
+		v |= uint(registers[b+1]) << (8 - shift)
+	}
+	return uint8(v & hllRegisterMax)
+}
+
+func setDenseRegister(registers []byte, index int, v uint8) {
+	bit := index * hllRegisterBits
+	b, shift := bit/8, uint(bit%8)
+	registers[b] = registers[b]&^byte(hllRegisterMax<<shift) | v<<shift
+	if b+1 < len(registers) {
+		registers[b+1] = registers[b+1]&^byte(hllRegisterMax>>(8-shift)) | v>>(8-shift)
+	}
+}
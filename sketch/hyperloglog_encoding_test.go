@@ -0,0 +1,154 @@
+package sketch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redisEmptyHLL is the value of a key created by PFADD without elements
+var redisEmptyHLL = []byte("HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xff")
+
+func TestHyperLogLogMarshalBinary(t *testing.T) {
+	for _, p := range []int{MinHyperLogLogPrecision, 11, RedisHyperLogLogPrecision, MaxHyperLogLogPrecision} {
+		for _, n := range []int{0, 5, 200, 50_000} {
+			t.Run(fmt.Sprintf("p=%d n=%d", p, n), func(t *testing.T) {
+				h := NewHyperLogLog(p)
+				for i := 0; i < n; i++ {
+					h.AddString(fmt.Sprintf("user-%d", i))
+				}
+
+				data, err := h.MarshalBinary()
+				require.NoError(t, err)
+				assert.Equal(t, hllMagic, string(data[:4]))
+				if h.registers != nil {
+					assert.Len(t, data, hllHeaderSize+h.denseSize())
+				} else {
+					assert.Less(t, len(data), hllHeaderSize+h.denseSize(), "sparse is smaller than dense")
+				}
+
+				var got HyperLogLog
+				require.NoError(t, got.UnmarshalBinary(data))
+				assert.Equal(t, p, got.Precision())
+				assert.Equal(t, h.Count(), got.Count())
+				again, err := got.MarshalBinary()
+				require.NoError(t, err)
+				assert.Equal(t, data, again)
+			})
+		}
+	}
+}
+
+func TestHyperLogLogRedisFormat(t *testing.T) {
+	h := NewHyperLogLog(RedisHyperLogLogPrecision)
+	data, err := h.MarshalBinary()
+	require.NoError(t, err)
+	// the same as Redis, except for the cached cardinality marked invalid
+	want := slices.Clone(redisEmptyHLL)
+	want[15] |= 0x80
+	assert.Equal(t, want, data)
+
+	require.NoError(t, h.UnmarshalBinary(redisEmptyHLL))
+	assert.Equal(t, uint64(0), h.Count())
+	assert.Equal(t, RedisHyperLogLogPrecision, h.Precision())
+
+	// registers 0 and 1 at 3, register 16383 at 32, written by hand with every opcode
+	sparse := slices.Concat(redisEmptyHLL[:16], []byte{
+		0x80 | 2<<2 | 1,   // VAL 3 x2
+		0x40 | 0x3f, 0xfb, // XZERO 16380
+		0x00,         // ZERO 1
+		0x80 | 31<<2, // VAL 32 x1
+	})
+	require.NoError(t, h.UnmarshalBinary(sparse))
+	assert.Equal(t, map[uint32]uint8{0: 3, 1: 3, 16383: 32}, h.sparse)
+	assert.Equal(t, uint64(3), h.Count())
+
+	// a dense value with register 2 at 51, the largest rank at precision 14
+	dense := make([]byte, hllHeaderSize+12288)
+	copy(dense, "HYLL")
+	setDenseRegister(dense[hllHeaderSize:], 2, 51)
+	setDenseRegister(dense[hllHeaderSize:], 16383, 1)
+	require.NoError(t, h.UnmarshalBinary(dense))
+	assert.Equal(t, uint8(51), h.registers[2])
+	assert.Equal(t, uint8(1), h.registers[16383])
+	assert.Equal(t, uint8(0), h.registers[3])
+	assert.Equal(t, uint64(2), h.Count())
+}
+
+func TestHyperLogLogRedisGolden(t *testing.T) {
+	// testdata holds the value of GET hll after PFADD hll user-0 user-1 ... user-<n-1> on an empty key,
+	// before any PFCOUNT caches the cardinality in the header, generated with the sparse and dense
+	// update code and hllCount of Redis 7 hyperloglog.c built standalone
+	tests := []struct {
+		file     string
+		n        int
+		encoding byte
+		pfcount  uint64
+	}{
+		{"redis_sparse.hll", 500, hllSparse, 496},
+		{"redis_dense.hll", 10_000, hllDense, 9957},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			golden, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			require.NoError(t, err)
+			require.Equal(t, tt.encoding, golden[4])
+
+			h := NewHyperLogLog(RedisHyperLogLogPrecision)
+			for i := 0; i < tt.n; i++ {
+				h.AddString(fmt.Sprintf("user-%d", i))
+			}
+			assert.Equal(t, tt.pfcount, h.Count())
+			data, err := h.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, golden, data)
+
+			var got HyperLogLog
+			require.NoError(t, got.UnmarshalBinary(golden))
+			assert.Equal(t, tt.pfcount, got.Count())
+		})
+	}
+}
+
+func TestHyperLogLogDenseRegisters(t *testing.T) {
+	registers := make([]byte, (100*hllRegisterBits+7)/8)
+	for i := 0; i < 100; i++ {
+		setDenseRegister(registers, i, uint8(i%64))
+	}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, uint8(i%64), denseRegister(registers, i))
+	}
+	setDenseRegister(registers, 50, 0)
+	assert.Equal(t, uint8(0), denseRegister(registers, 50))
+	assert.Equal(t, uint8(49), denseRegister(registers, 49))
+	assert.Equal(t, uint8(51), denseRegister(registers, 51))
+}
+
+func TestHyperLogLogUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", []byte("HYLX\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xff")},
+		{"unknown encoding", []byte("HYLL\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xff")},
+		{"bad precision", []byte("HYLL\x01\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x7f\xff")},
+		{"short sparse", redisEmptyHLL[:len(redisEmptyHLL)-1]},
+		{"long sparse", append(slices.Clone(redisEmptyHLL), 0)},
+		{"dense size", []byte("HYLL\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHyperLogLog(10)
+			h.AddString("kept")
+			assert.ErrorIs(t, h.UnmarshalBinary(tt.data), ErrInvalidEncoding)
+			assert.Equal(t, uint64(1), h.Count(), "failed unmarshal must not modify the HyperLogLog")
+			assert.Equal(t, 10, h.Precision())
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	h := NewHyperLogLog(RedisHyperLogLogPrecision)
+	assert.Equal(t, 14, h.Precision())
+	assert.Equal(t, uint64(0), h.Count())
+
+	assert.True(t, h.AddString("player:1"))
+	assert.False(t, h.AddString("player:1"), "adding an element twice changes nothing")
+	assert.True(t, h.Add([]byte("player:2")))
+	assert.Equal(t, uint64(2), h.Count())
+
+	assert.Panics(t, func() { NewHyperLogLog(3) })
+	assert.Panics(t, func() { NewHyperLogLog(19) })
+}
+
+func TestHyperLogLogAccuracy(t *testing.T) {
+	for _, p := range []int{MinHyperLogLogPrecision, 10, RedisHyperLogLogPrecision, MaxHyperLogLogPrecision} {
+		stdError := 1.04 / math.Sqrt(float64(int(1)<<p))
+		for _, n := range []int{10, 1000, 100_000} {
+			t.Run(fmt.Sprintf("p=%d n=%d", p, n), func(t *testing.T) {
+				h := NewHyperLogLog(p)
+				for i := 0; i < n; i++ {
+					h.AddString(fmt.Sprintf("user-%d", i))
+				}
+				// within four standard errors, and small sets are counted almost exactly
+				tolerance := max(4*stdError*float64(n), 1)
+				assert.InDelta(t, n, h.Count(), tolerance)
+			})
+		}
+	}
+}
+
+func TestHyperLogLogSparseToDense(t *testing.T) {
+	h := NewHyperLogLog(10)
+	for i := 0; h.registers == nil; i++ {
+		h.AddString(fmt.Sprintf("user-%d", i))
+		require.Less(t, i, 10_000)
+	}
+	assert.Nil(t, h.sparse)
+	assert.InDelta(t, h.maxSparse(), h.Count(), 20)
+
+	// ranks above the sparse limit switch to dense immediately
+	h = NewHyperLogLog(RedisHyperLogLogPrecision)
+	assert.True(t, h.update(3, hllMaxSparseRank+1))
+	assert.NotNil(t, h.registers)
+	assert.Equal(t, uint8(hllMaxSparseRank+1), h.registers[3])
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, b := NewHyperLogLog(12), NewHyperLogLog(12)
+	for i := 0; i < 30_000; i++ {
+		a.AddString(fmt.Sprintf("user-%d", i))
+	}
+	for i := 20_000; i < 50_000; i++ {
+		b.AddString(fmt.Sprintf("user-%d", i))
+	}
+	small := NewHyperLogLog(12)
+	small.AddString("user-0")
+	small.AddString("someone")
+
+	require.NoError(t, a.Merge(b))
+	require.NoError(t, a.Merge(small))
+	require.NoError(t, a.Merge(a))
+	assert.InDelta(t, 50_001, a.Count(), 50_000*0.05)
+
+	// merging into a sparse HyperLogLog
+	require.NoError(t, small.Merge(b))
+	assert.InDelta(t, 30_002, small.Count(), 30_000*0.05)
+
+	assert.ErrorIs(t, a.Merge(NewHyperLogLog(14)), ErrIncompatible)
+}
+
+func TestHyperLogLogConcurrency(t *testing.T) {
+	h := NewHyperLogLog(RedisHyperLogLogPrecision)
+	other := NewHyperLogLog(RedisHyperLogLogPrecision)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 40_000; i += 8 {
+				h.AddString(fmt.Sprintf("user-%d", i))
+				other.AddString(fmt.Sprintf("user-%d", i))
+				if i%1000 == 0 {
+					_ = h.Count()
+					_ = h.Merge(other)
+					_ = other.Merge(h)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.InDelta(t, 40_000, h.Count(), 40_000*0.03)
+}
+
+func TestMurmurHash64A(t *testing.T) {
+	assert.Equal(t, uint64(0), murmurHash64A(nil, 0))
+	// every tail length is hashed
+	seen := make(map[uint64]bool)
+	for n := 0; n <= 17; n++ {
+		seen[murmurHash64A(make([]byte, n), hllSeed)] = true
+	}
+	assert.Len(t, seen, 18)
+	assert.NotEqual(t, murmurHash64A([]byte("abc"), hllSeed), murmurHash64A([]byte("abd"), hllSeed))
+
+	// MurmurHash64A of Redis with the HyperLogLog seed, for each tail length and a full block
+	tests := []struct {
+		data string
+		want uint64
+	}{
+		{"", 0xd8dfea6585bc9732},
+		{"a", 0x53d2470a9b43b1a7},
+		{"ab", 0x0eaed676437142cf},
+		{"abc", 0x77ec90aeb374e502},
+		{"abcdefg", 0x22fe613bb08c9602},
+		{"abcdefgh", 0xf3a65df559914567},
+		{"abcdefghi", 0x834fba4d9152daf7},
+		{"hello world", 0xa919bc3051f624b7},
+		{"The quick brown fox jumps over the lazy dog", 0x51606c5c5b561ace},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, murmurHash64A([]byte(tt.data), hllSeed), "%q", tt.data)
+	}
+}
+
+func BenchmarkHyperLogLogAdd(b *testing.B) {
+	h := NewHyperLogLog(RedisHyperLogLogPrecision)
+	data := make([][]byte, 1<<16)
+	for i := range data {
+		data[i] = []byte(fmt.Sprintf("user-%d", i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Add(data[i&(len(data)-1)])
+	}
+}
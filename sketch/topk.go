@@ -0,0 +1,124 @@
+package sketch
+
+import (
+	"container/heap"
+	"slices"
+	"sync"
+
+	"github.com/vulcan-frame/vulcan-pkg-tool/bloom"
+)
+
+// Entry is a key with its estimated count
+type Entry[T any] struct {
+	Key   T
+	Count uint64
+}
+
+// TopK represents a thread-safe heavy-hitters tracker: it keeps the k keys with the highest
+// estimated counts of a CountMinSketch, using memory independent of the number of distinct keys.
+type TopK[T comparable] struct {
+	sketch *CountMinSketch[T]
+
+	mutex sync.Mutex
+	k     int
+	heap  entryHeap[T]
+	index map[T]int // position of the tracked keys in heap
+}
+
+// NewTopK create heavy-hitters tracker of the k hottest keys,
+// epsilon and delta configure the sketch like in NewCountMinSketch
+func NewTopK[T comparable](k int, epsilon, delta float64, hash bloom.Hasher[T]) *TopK[T] {
+	if k <= 0 {
+		panic("top-k size must be positive")
+	}
+	t := &TopK[T]{
+		sketch: NewCountMinSketch(epsilon, delta, hash),
+		k:      k,
+		index:  make(map[T]int, k),
+	}
+	t.heap.index = t.index
+	return t
+}
+
+// Add adds count occurrences of key and returns its new estimate
+func (t *TopK[T]) Add(key T, count uint64) uint64 {
+	estimate := t.sketch.Add(key, count)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if i, ok := t.index[key]; ok {
+		// concurrent adds may complete out of order, estimates only grow
+		t.heap.entries[i].Count = max(t.heap.entries[i].Count, estimate)
+		heap.Fix(&t.heap, i)
+		return estimate
+	}
+	if len(t.heap.entries) < t.k {
+		heap.Push(&t.heap, Entry[T]{Key: key, Count: estimate})
+		return estimate
+	}
+	if estimate > t.heap.entries[0].Count {
+		delete(t.index, t.heap.entries[0].Key)
+		t.heap.entries[0] = Entry[T]{Key: key, Count: estimate}
+		t.index[key] = 0
+		heap.Fix(&t.heap, 0)
+	}
+	return estimate
+}
+
+// List returns the tracked keys by decreasing estimated count
+func (t *TopK[T]) List() []Entry[T] {
+	t.mutex.Lock()
+	entries := slices.Clone(t.heap.entries)
+	t.mutex.Unlock()
+
+	slices.SortFunc(entries, func(a, b Entry[T]) int {
+		switch {
+		case a.Count > b.Count:
+			return -1
+		case a.Count < b.Count:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return entries
+}
+
+// Estimate returns the estimated count of key, tracked or not
+func (t *TopK[T]) Estimate(key T) uint64 {
+	return t.sketch.Estimate(key)
+}
+
+// Sketch returns the underlying sketch
+func (t *TopK[T]) Sketch() *CountMinSketch[T] {
+	return t.sketch
+}
+
+// entryHeap is a min-heap of entries by count, keeping index up to date
+type entryHeap[T comparable] struct {
+	entries []Entry[T]
+	index   map[T]int
+}
+
+func (h *entryHeap[T]) Len() int { return len(h.entries) }
+
+func (h *entryHeap[T]) Less(i, j int) bool { return h.entries[i].Count < h.entries[j].Count }
+
+func (h *entryHeap[T]) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].Key] = i
+	h.index[h.entries[j].Key] = j
+}
+
+func (h *entryHeap[T]) Push(x any) {
+	e := x.(Entry[T])
+	h.index[e.Key] = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *entryHeap[T]) Pop() any {
+	e := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	delete(h.index, e.Key)
+	return e
+}
@@ -0,0 +1,84 @@
+package sketch
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcan-frame/vulcan-pkg-tool/bloom"
+)
+
+func TestTopK(t *testing.T) {
+	top := NewTopK(3, 0.001, 0.01, bloom.StringHasher)
+	assert.Empty(t, top.List())
+
+	top.Add("a", 5)
+	top.Add("b", 1)
+	top.Add("c", 3)
+	top.Add("d", 2) // evicts b
+	assert.Equal(t, []Entry[string]{{"a", 5}, {"c", 3}, {"d", 2}}, top.List())
+
+	top.Add("b", 10) // b comes back with its whole count
+	assert.Equal(t, []Entry[string]{{"b", 11}, {"a", 5}, {"c", 3}}, top.List())
+
+	top.Add("c", 4)
+	assert.Equal(t, []Entry[string]{{"b", 11}, {"c", 7}, {"a", 5}}, top.List())
+	assert.Equal(t, uint64(2), top.Estimate("d"))
+
+	assert.Panics(t, func() { NewTopK(0, 0.01, 0.01, bloom.StringHasher) })
+}
+
+func TestTopKHeavyHitters(t *testing.T) {
+	top := NewTopK(10, 0.0001, 0.01, bloom.Int64Hasher)
+	rnd := rand.New(rand.NewSource(2))
+	counts := make(map[int64]uint64)
+	for i := 0; i < 100_000; i++ {
+		// ten hot players among a million
+		id := int64(rnd.Intn(1_000_000))
+		if rnd.Intn(2) == 0 {
+			id = int64(rnd.Intn(10)) * 1_000_003
+		}
+		counts[id]++
+		top.Add(id, 1)
+	}
+
+	list := top.List()
+	assert.Len(t, list, 10)
+	for _, e := range list {
+		assert.Zero(t, e.Key%1_000_003, "unexpected key %d", e.Key)
+		assert.GreaterOrEqual(t, e.Count, counts[e.Key])
+	}
+}
+
+func TestTopKConcurrency(t *testing.T) {
+	top := NewTopK(5, 0.001, 0.01, bloom.StringHasher)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				top.Add(fmt.Sprintf("hot-%d", i%5), 1)
+				top.Add(fmt.Sprintf("cold-%d-%d", g, i), 1)
+			}
+			_ = top.List()
+		}(g)
+	}
+	wg.Wait()
+
+	list := top.List()
+	assert.Len(t, list, 5)
+	for _, e := range list {
+		assert.Contains(t, e.Key, "hot-")
+		assert.GreaterOrEqual(t, e.Count, uint64(3200))
+	}
+}
+
+func BenchmarkTopKAdd(b *testing.B) {
+	top := NewTopK(100, 0.0001, 0.01, bloom.Int64Hasher)
+	for i := 0; i < b.N; i++ {
+		top.Add(int64(i&0xffff), 1)
+	}
+}